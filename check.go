@@ -0,0 +1,230 @@
+package migo
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	schema "github.com/lestrrat/go-jsschema"
+)
+
+type Check struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+}
+
+type Checks []Check
+
+func (c Checks) Len() int {
+	return len(c)
+}
+
+func (c Checks) Less(i, j int) bool {
+	return c[i].Name < c[j].Name
+}
+
+func (c Checks) Swap(i, j int) {
+	c[i], c[j] = c[j], c[i]
+}
+
+func NewCheck(name, expression string) Check {
+	return Check{Name: name, Expression: expression}
+}
+
+func (c Check) isUpdatedFrom(target Check) (bool, error) {
+	if c.Name != target.Name {
+		return false, errors.New("the target check name is wrong")
+	}
+	return !reflect.DeepEqual(c, target), nil
+}
+
+func (c Check) query() string {
+	return fmt.Sprintf("CONSTRAINT %s CHECK (%s)", c.Name, c.Expression)
+}
+
+const checkNameSuffix = "_chk"
+
+// checkKinds are the kinds of the checks derived from the validation keywords of a column.
+var checkKinds = []string{"range", "length", "pattern", "enum", "schema"}
+
+// checkName names the check constraint after the table, column and kind. A name
+// over the identifier limit is cut, and a hash of the whole name keeps it unique.
+func checkName(t Table, c Column, kind string) string {
+	name := fmt.Sprintf("%s_%s_%s", t.Name, c.Name, kind)
+	if len(name)+len(checkNameSuffix) <= maxIdentifierLength {
+		return name + checkNameSuffix
+	}
+	h := fmt.Sprintf("%x", sha256.Sum256([]byte(name)))[:8]
+	return fmt.Sprintf("%s_%s%s", name[:maxIdentifierLength-len(h)-len(checkNameSuffix)-1], h, checkNameSuffix)
+}
+
+func number(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+func literal(i interface{}) (string, error) {
+	switch v := i.(type) {
+	case string:
+		return quote(v), nil
+	case float64:
+		return number(v), nil
+	case bool:
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	}
+	return "", fmt.Errorf("fail to convert %v to SQL literal", i)
+}
+
+func rangeExpression(name string, s schema.Schema) string {
+	min, max := s.Minimum, s.Maximum
+	exclusive := s.ExclusiveMinimum.Val || s.ExclusiveMaximum.Val
+	if min.Initialized && max.Initialized && !exclusive {
+		return fmt.Sprintf("%s BETWEEN %s AND %s", name, number(min.Val), number(max.Val))
+	}
+
+	e := []string{}
+	if min.Initialized {
+		op := ">="
+		if s.ExclusiveMinimum.Val {
+			op = ">"
+		}
+		e = append(e, fmt.Sprintf("%s %s %s", name, op, number(min.Val)))
+	}
+	if max.Initialized {
+		op := "<="
+		if s.ExclusiveMaximum.Val {
+			op = "<"
+		}
+		e = append(e, fmt.Sprintf("%s %s %s", name, op, number(max.Val)))
+	}
+	return strings.Join(e, " AND ")
+}
+
+func lengthExpression(name string, s schema.Schema) string {
+	min, max := s.MinLength, s.MaxLength
+	length := fmt.Sprintf("CHAR_LENGTH(%s)", name)
+	switch {
+	case min.Initialized && max.Initialized:
+		return fmt.Sprintf("%s BETWEEN %d AND %d", length, min.Val, max.Val)
+	case min.Initialized:
+		return fmt.Sprintf("%s >= %d", length, min.Val)
+	case max.Initialized:
+		return fmt.Sprintf("%s <= %d", length, max.Val)
+	}
+	return ""
+}
+
+func enumExpression(name string, s schema.Schema) (string, error) {
+	values := []string{}
+	for _, v := range s.Enum {
+		if v == nil {
+			continue
+		}
+		l, err := literal(v)
+		if err != nil {
+			return "", errors.Wrap(err, "reading enum")
+		}
+		values = append(values, l)
+	}
+	if len(values) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("%s IN (%s)", name, strings.Join(values, ",")), nil
+}
+
+func findChecks(t Table, c Column, s schema.Schema) (Checks, error) {
+	checks := Checks{}
+	if e := rangeExpression(c.Name, s); e != "" {
+		checks = append(checks, NewCheck(checkName(t, c, "range"), e))
+	}
+	if e := lengthExpression(c.Name, s); e != "" {
+		checks = append(checks, NewCheck(checkName(t, c, "length"), e))
+	}
+	if s.Pattern != nil {
+		e := fmt.Sprintf("REGEXP_LIKE(%s, %s)", c.Name, quote(s.Pattern.String()))
+		checks = append(checks, NewCheck(checkName(t, c, "pattern"), e))
+	}
+	e, err := enumExpression(c.Name, s)
+	if err != nil {
+		return nil, err
+	}
+	if e != "" {
+		checks = append(checks, NewCheck(checkName(t, c, "enum"), e))
+	}
+	return checks, nil
+}
+
+func isJSON(s string) bool {
+	return strings.HasPrefix(strings.ToLower(s), "json")
+}
+
+// stripExtensions removes the column and table blocks of migo from the schema
+// and its sub-schemas, leaving the properties named so.
+func stripExtensions(m map[string]interface{}) {
+	delete(m, "column")
+	delete(m, "table")
+
+	for k, v := range m {
+		switch k {
+		case "properties", "patternProperties", "definitions":
+			if ss, ok := v.(map[string]interface{}); ok {
+				for _, sub := range ss {
+					stripSubSchema(sub)
+				}
+			}
+		case "items", "additionalItems", "additionalProperties", "not", "allOf", "anyOf", "oneOf":
+			stripSubSchema(v)
+		}
+	}
+}
+
+// stripSubSchema strips the extensions of a sub-schema or a list of them.
+func stripSubSchema(v interface{}) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		stripExtensions(t)
+	case []interface{}:
+		for _, e := range t {
+			stripSubSchema(e)
+		}
+	}
+}
+
+func serializeSchema(s schema.Schema) (string, error) {
+	b, err := json.Marshal(&s)
+	if err != nil {
+		return "", errors.Wrap(err, "convert schema to json")
+	}
+
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return "", errors.Wrap(err, "convert json to map")
+	}
+	stripExtensions(m)
+
+	b, err = json.Marshal(m)
+	if err != nil {
+		return "", errors.Wrap(err, "convert map to json")
+	}
+	return string(b), nil
+}
+
+func findJSONSchemaCheck(t Table, c Column, s schema.Schema) (Checks, error) {
+	if !isJSON(c.Type) || len(s.Properties) == 0 {
+		return nil, nil
+	}
+
+	j, err := serializeSchema(s)
+	if err != nil {
+		return nil, errors.Wrapf(err, "serializing sub-schema of column %s", c.Name)
+	}
+	e := fmt.Sprintf("JSON_SCHEMA_VALID(%s, %s)", quote(j), c.Name)
+	return Checks{NewCheck(checkName(t, c, "schema"), e)}, nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
 
@@ -43,6 +44,39 @@ func SetupCmd() *cli.App {
 			Name:  "state, s",
 			Usage: "Load internal state from `State` YAML formatted file.",
 		},
+		cli.BoolFlag{
+			Name:  "comment-from-description",
+			Usage: "Use the JSON Schema description as the column comment when comment is omitted",
+		},
+		cli.BoolFlag{
+			Name:  "check-constraint",
+			Usage: "Derive CHECK constraints from JSON Schema validation keywords (MySQL 8.0.16+)",
+		},
+		cli.BoolFlag{
+			Name:  "json-schema-check",
+			Usage: "Validate JSON columns against their sub-schema with JSON_SCHEMA_VALID (MySQL 8.0.17+)",
+		},
+		cli.StringFlag{
+			Name:  "dialect",
+			Value: "mysql",
+			Usage: "SQL `dialect` used to infer column types omitted in Schema (mysql or mariadb)",
+		},
+		cli.StringFlag{
+			Name:  "type-mapping",
+			Usage: "Load column type mapping overrides from `TypeMapping` YAML formatted file.",
+		},
+		cli.BoolFlag{
+			Name:  "expand-contract",
+			Usage: "Apply column renames in the expand phase, leaving the old column and sync triggers until contract",
+		},
+		cli.BoolFlag{
+			Name:  "contract",
+			Usage: "Drop the old columns and sync triggers of the changes applied in the expand phase",
+		},
+		cli.StringFlag{
+			Name:  "hooks",
+			Usage: "Load migration hooks from `Hooks` YAML formatted file.",
+		},
 	}
 
 	app.Commands = []cli.Command{
@@ -71,6 +105,100 @@ func SetupCmd() *cli.App {
 			Usage:  "insert seed record",
 			Action: Seed,
 		},
+		{
+			Name:   "validate",
+			Usage:  "lint Schema file without connecting to database",
+			Action: Validate,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "rules",
+					Usage: "Load lint rules from `Rules` YAML formatted file.",
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Value: "text",
+					Usage: "Output `format` of the problems (text, json or sarif)",
+				},
+			},
+		},
+		{
+			Name:      "diff",
+			Usage:     "get migration plan from old Schema file to new Schema file",
+			ArgsUsage: "old.yml new.yml | --rev revision schema.yml",
+			Action:    Diff,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "rev",
+					Usage: "Read old Schema file at git `revision`",
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Value: "text",
+					Usage: "Output `format` of the plan (text, json or sql)",
+				},
+			},
+		},
+		{
+			Name:      "compare",
+			Usage:     "get migration plan making the schema of target environment match source environment",
+			ArgsUsage: "-e source -e target",
+			Action:    Compare,
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "environment, e",
+					Usage: "Compare schemas of the source and target `environment`s",
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Value: "text",
+					Usage: "Output `format` of the plan (text, json or sql)",
+				},
+			},
+		},
+		{
+			Name:   "ddl",
+			Usage:  "print SQL script creating the schema",
+			Action: DDL,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "from-state",
+					Usage: "Read the schema from State file instead of Schema file",
+				},
+			},
+		},
+		{
+			Name:   "import",
+			Usage:  "print Schema file read from SQL script",
+			Action: Import,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "from-sql",
+					Usage: "Read CREATE TABLE, CREATE INDEX and ALTER TABLE statements from SQL `file`",
+				},
+			},
+		},
+		{
+			Name:   "meta-schema",
+			Usage:  "print JSON Schema of the table, column and foreign_key blocks",
+			Action: MetaSchema,
+		},
+		{
+			Name:  "partitions",
+			Usage: "manage table partitions",
+			Subcommands: []cli.Command{
+				{
+					Name:   "maintain",
+					Usage:  "add and drop the partitions following the partition policy",
+					Action: MaintainPartitions,
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "plan",
+							Usage: "show the maintenance plan without applying it",
+						},
+					},
+				},
+			},
+		},
 	}
 
 	return app
@@ -88,6 +216,90 @@ func Seed(c *cli.Context) error {
 	return nil
 }
 
+func MaintainPartitions(c *cli.Context) error {
+	op, err := migo.NewPartitionOption(c)
+	if err != nil {
+		return errors.Wrap(err, "parsing option")
+	}
+
+	if err := migo.MaintainPartitions(op); err != nil {
+		return errors.Wrap(err, "MAINTAIN PARTITIONS")
+	}
+	return nil
+}
+
+func Validate(c *cli.Context) error {
+	op, err := migo.NewValidateOption(c)
+	if err != nil {
+		return errors.Wrap(err, "parsing option")
+	}
+
+	ps, err := migo.Validate(op)
+	if err != nil {
+		return errors.Wrap(err, "VALIDATE")
+	}
+	if err := ps.Write(os.Stdout, op.Format, op.Migrate.SchemaFile); err != nil {
+		return errors.Wrap(err, "VALIDATE")
+	}
+	if ps.HasError() {
+		return errors.New("VALIDATE: schema has errors")
+	}
+	return nil
+}
+
+func Diff(c *cli.Context) error {
+	op, err := migo.NewDiffOption(c)
+	if err != nil {
+		return errors.Wrap(err, "parsing option")
+	}
+
+	if err := migo.Diff(op, os.Stdout); err != nil {
+		return errors.Wrap(err, "DIFF")
+	}
+	return nil
+}
+
+func Compare(c *cli.Context) error {
+	op, err := migo.NewCompareOption(c)
+	if err != nil {
+		return errors.Wrap(err, "parsing option")
+	}
+
+	if err := migo.Compare(op, os.Stdout); err != nil {
+		return errors.Wrap(err, "COMPARE")
+	}
+	return nil
+}
+
+func DDL(c *cli.Context) error {
+	op, err := migo.NewDDLOption(c)
+	if err != nil {
+		return errors.Wrap(err, "parsing option")
+	}
+
+	if err := migo.DDL(op, os.Stdout); err != nil {
+		return errors.Wrap(err, "DDL")
+	}
+	return nil
+}
+
+func Import(c *cli.Context) error {
+	op, err := migo.NewImportOption(c)
+	if err != nil {
+		return errors.Wrap(err, "parsing option")
+	}
+
+	if err := migo.Import(op, os.Stdout, os.Stderr); err != nil {
+		return errors.Wrap(err, "IMPORT")
+	}
+	return nil
+}
+
+func MetaSchema(c *cli.Context) error {
+	fmt.Println(migo.MetaSchema)
+	return nil
+}
+
 func Run(c *cli.Context) error {
 	op, err := migo.NewMigrateOption(c)
 	if err != nil {
@@ -12,14 +12,21 @@ import (
 )
 
 type Column struct {
-	Id            string `json:"id"`
-	Name          string `json:"name"`
-	Type          string `json:"type"`
-	Unique        bool   `json:"unique"`
-	AutoIncrement bool   `json:"auto_increment"`
-	AutoUpdate    bool   `json:"auto_update"`
-	NotNull       bool   `json:"not_null"`
-	Default       string `json:"default"`
+	Id            string  `json:"id"`
+	Name          string  `json:"name"`
+	Type          string  `json:"type"`
+	Unique        bool    `json:"unique"`
+	AutoIncrement bool    `json:"auto_increment"`
+	AutoUpdate    bool    `json:"auto_update"`
+	NotNull       bool    `json:"not_null"`
+	Default       Default `json:"default"`
+	Charset       string  `json:"charset"`
+	Collation     string  `json:"collation"`
+	Comment       string  `json:"comment"`
+	Inferred      bool    `json:"inferred"`
+	Expression    string  `json:"expression"`
+	Storage       string  `json:"storage"`
+	RenamedFrom   string  `json:"renamed_from"`
 }
 type Columns []Column
 
@@ -39,14 +46,48 @@ func NewColumn(id string) Column {
 	return Column{Id: id}
 }
 
+func (c Column) columnType() ColumnType {
+	t, err := ParseColumnType(c.Type)
+	if err != nil {
+		return ColumnType{Base: c.Type}
+	}
+	return t
+}
+
+func (c Column) normalize() Column {
+	c.Id = ""
+	c.Type = c.columnType().String()
+	c.Inferred = false
+	c.RenamedFrom = ""
+	return c
+}
+
+// isSameAs reports whether the column is the current column itself or
+// renamed from it by a renamed_from hint. Only the hint of the new column
+// counts, so that an old hint does not match a new column reusing the Id.
+func (c Column) isSameAs(current Column) bool {
+	if c.Id == current.Id {
+		return true
+	}
+	return c.RenamedFrom != "" && c.RenamedFrom == current.Id
+}
+
 func (c Column) isUpdatedFrom(target Column) (bool, error) {
-	if c.Id != target.Id {
+	if !c.isSameAs(target) {
 		return false, errors.New("the target column ID is wrong")
 	}
-	return !reflect.DeepEqual(c, target), nil
+	return !reflect.DeepEqual(c.normalize(), target.normalize()), nil
+}
+
+// looksLike reports whether the target seems to be the same column with
+// another Id, which is used to warn about a drop and add without a hint.
+func (c Column) looksLike(target Column) bool {
+	c, target = c.normalize(), target.normalize()
+	c.Name, target.Name = "", ""
+	return reflect.DeepEqual(c, target)
 }
 
-func (c *Column) read(schema schema.Schema) error {
+func (c *Column) read(schema schema.Schema, required bool, op SchemaOption) error {
 	if hasNotColumn(schema) {
 		return nil
 	}
@@ -59,11 +100,103 @@ func (c *Column) read(schema schema.Schema) error {
 		return errors.Wrap(err, "convert to column")
 	}
 
+	if c.Default.isEmpty() && schema.Default != nil && !c.isGenerated() {
+		c.Default, err = NewDefault(schema.Default)
+		if err != nil {
+			return errors.Wrapf(err, "reading default of column %s", c.Name)
+		}
+	}
+
+	if c.Type == "" {
+		c.Type, err = op.typeMapping.infer(schema)
+		if err != nil {
+			return errors.Wrapf(err, "inferring type of column %s", c.Name)
+		}
+		c.Inferred = true
+	}
+
+	if m, ok := schema.Extras["column"].(map[string]interface{}); ok && m["not_null"] == nil && c.Inferred {
+		c.NotNull = required
+	}
+
+	if c.Comment == "" && op.CommentFromDescription {
+		c.Comment = schema.Description
+	}
+	return c.validate()
+}
+
+const (
+	storageVirtual = "virtual"
+	storageStored  = "stored"
+)
+
+func (c Column) isGenerated() bool {
+	return c.Expression != ""
+}
+
+func (c Column) isStored() bool {
+	return c.isGenerated() && strings.ToLower(c.Storage) == storageStored
+}
+
+func (c Column) validate() error {
+	if !c.isGenerated() {
+		if c.Storage != "" {
+			return fmt.Errorf("column %s has storage but no expression", c.Name)
+		}
+		return nil
+	}
+
+	switch strings.ToLower(c.Storage) {
+	case "", storageVirtual, storageStored:
+	default:
+		return fmt.Errorf("storage of generated column %s should be virtual or stored", c.Name)
+	}
+	if !c.Default.isEmpty() {
+		return fmt.Errorf("generated column %s can not have default", c.Name)
+	}
+	if c.AutoIncrement {
+		return fmt.Errorf("generated column %s can not be auto_increment", c.Name)
+	}
 	return nil
 }
 
+// canChangeFrom reports whether the column can be changed from the target
+// with CHANGE COLUMN. MySQL can not switch the storage of a generated
+// column, nor turn a virtual column into a regular one or vice versa.
+func (c Column) canChangeFrom(target Column) bool {
+	if c.isGenerated() && target.isGenerated() {
+		return c.isStored() == target.isStored()
+	}
+	if c.isGenerated() || target.isGenerated() {
+		return c.isStored() || target.isStored()
+	}
+	return true
+}
+
+func (c Column) generatedQuery() string {
+	storage := "VIRTUAL"
+	if c.isStored() {
+		storage = "STORED"
+	}
+	return fmt.Sprintf("GENERATED ALWAYS AS (%s) %s", c.Expression, storage)
+}
+
+func quote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
 func (c Column) query() string {
 	s := []string{c.Name, c.Type}
+	if c.Charset != "" {
+		s = append(s, fmt.Sprintf("CHARACTER SET %s", c.Charset))
+	}
+	if c.Collation != "" {
+		s = append(s, fmt.Sprintf("COLLATE %s", c.Collation))
+	}
+	if c.isGenerated() {
+		s = append(s, c.generatedQuery())
+	}
 	if c.AutoIncrement {
 		s = append(s, "AUTO_INCREMENT")
 	}
@@ -74,17 +207,22 @@ func (c Column) query() string {
 		s = append(s, "UNIQUE")
 	}
 
-	if c.Default != "" && !isDatetime(c.Type) {
-		s = append(s, fmt.Sprintf("DEFAULT '%s'", c.Default))
+	t := c.columnType()
+	if t.isDatetime() && c.AutoUpdate && !c.isGenerated() {
+		s = append(s, fmt.Sprintf("ON UPDATE %s", t.currentTimestamp()))
 	}
 
-	if isDatetime(c.Type) {
-		if c.AutoUpdate {
-			s = append(s, fmt.Sprintf("ON UPDATE CURRENT_TIMESTAMP%s", digit(c.Type)))
-		}
-		if c.Default == "" {
-			s = append(s, fmt.Sprintf("DEFAULT CURRENT_TIMESTAMP%s", digit(c.Type)))
-		}
+	switch {
+	case c.isGenerated():
+	case !c.Default.isEmpty():
+		s = append(s, fmt.Sprintf("DEFAULT %s", c.Default.query()))
+	case t.isDatetime() && (c.NotNull || c.AutoUpdate):
+		// a nullable datetime column without default is left NULL
+		s = append(s, fmt.Sprintf("DEFAULT %s", t.currentTimestamp()))
+	}
+
+	if c.Comment != "" {
+		s = append(s, fmt.Sprintf("COMMENT %s", quote(c.Comment)))
 	}
 	return strings.Join(s, " ")
 }
@@ -0,0 +1,238 @@
+package migo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ColumnType is a parsed SQL column type such as `int(11) unsigned`,
+// `decimal(10,2)`, `datetime(6)` or `enum('a','b')`.
+type ColumnType struct {
+	Base      string
+	Length    int
+	Precision int
+	Scale     int
+	Unsigned  bool
+	Zerofill  bool
+	Values    []string
+	Fsp       int
+}
+
+var columnTypeAliases = map[string]string{
+	"integer":           "int",
+	"int4":              "int",
+	"int8":              "bigint",
+	"middleint":         "mediumint",
+	"bool":              "tinyint",
+	"boolean":           "tinyint",
+	"dec":               "decimal",
+	"numeric":           "decimal",
+	"fixed":             "decimal",
+	"real":              "double",
+	"double precision":  "double",
+	"float8":            "double",
+	"float4":            "float",
+	"character":         "char",
+	"character varying": "varchar",
+	"long varchar":      "mediumtext",
+	"long":              "mediumtext",
+}
+
+func isIntegerType(base string) bool {
+	switch base {
+	case "tinyint", "smallint", "mediumint", "int", "bigint":
+		return true
+	}
+	return false
+}
+
+func isDecimalType(base string) bool {
+	return base == "decimal" || base == "float" || base == "double"
+}
+
+func isTemporalType(base string) bool {
+	return base == "datetime" || base == "timestamp" || base == "time"
+}
+
+func isEnumType(base string) bool {
+	return base == "enum" || base == "set"
+}
+
+func parseValues(s string) ([]string, error) {
+	values := []string{}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case ' ', ',':
+			continue
+		case '\'':
+		default:
+			return nil, fmt.Errorf("unexpected character %q in values", s[i])
+		}
+
+		v := []byte{}
+		for i++; ; i++ {
+			if i >= len(s) {
+				return nil, errors.New("unterminated quoted value")
+			}
+			if s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'' {
+				v = append(v, '\'')
+				i++
+				continue
+			}
+			if s[i] == '\'' {
+				break
+			}
+			v = append(v, s[i])
+		}
+		values = append(values, string(v))
+	}
+	return values, nil
+}
+
+func parseNumbers(s string) ([]int, error) {
+	ns := []int{}
+	for _, v := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", v)
+		}
+		ns = append(ns, n)
+	}
+	return ns, nil
+}
+
+// ParseColumnType parses the SQL column type string and normalizes
+// equivalent spellings, e.g. `INT` and `int(11)`, or `bool` and `tinyint(1)`.
+func ParseColumnType(s string) (ColumnType, error) {
+	t := ColumnType{}
+	s = strings.TrimSpace(s)
+	rest := ""
+	args := ""
+	if i := strings.Index(s, "("); i >= 0 {
+		j := strings.LastIndex(s, ")")
+		if j < i {
+			return t, fmt.Errorf("parenthesis is not closed in %s", s)
+		}
+		t.Base, args, rest = s[:i], s[i+1:j], s[j+1:]
+	} else {
+		t.Base = s
+	}
+
+	words := strings.Fields(strings.ToLower(t.Base + " " + rest))
+	base := []string{}
+	for _, w := range words {
+		switch w {
+		case "unsigned":
+			t.Unsigned = true
+		case "zerofill":
+			t.Zerofill = true
+		case "signed":
+		default:
+			base = append(base, w)
+		}
+	}
+	t.Base = strings.Join(base, " ")
+	if t.Base == "" {
+		return t, errors.New("column type is empty")
+	}
+
+	isBool := t.Base == "bool" || t.Base == "boolean"
+	if alias, ok := columnTypeAliases[t.Base]; ok {
+		t.Base = alias
+	}
+	if isBool {
+		t.Length = 1
+	}
+
+	if strings.TrimSpace(args) != "" {
+		if isEnumType(t.Base) {
+			values, err := parseValues(args)
+			if err != nil {
+				return t, errors.Wrapf(err, "parsing %s", s)
+			}
+			t.Values = values
+		} else {
+			ns, err := parseNumbers(args)
+			if err != nil {
+				return t, errors.Wrapf(err, "parsing %s", s)
+			}
+			switch {
+			case isDecimalType(t.Base):
+				t.Precision = ns[0]
+				if len(ns) > 1 {
+					t.Scale = ns[1]
+				}
+			case isTemporalType(t.Base):
+				t.Fsp = ns[0]
+			default:
+				t.Length = ns[0]
+			}
+		}
+	}
+
+	return t.normalize(), nil
+}
+
+func (t ColumnType) normalize() ColumnType {
+	if t.Zerofill {
+		t.Unsigned = true
+	}
+	switch {
+	case isIntegerType(t.Base):
+		// display width is meaningless except for zerofill and tinyint(1) as boolean
+		if !t.Zerofill && !(t.Base == "tinyint" && t.Length == 1) {
+			t.Length = 0
+		}
+	case t.Base == "decimal":
+		if t.Precision == 0 {
+			t.Precision = 10
+		}
+	case t.Base == "char" || t.Base == "binary" || t.Base == "bit":
+		if t.Length == 0 {
+			t.Length = 1
+		}
+	case t.Base == "year":
+		t.Length = 0
+	}
+	return t
+}
+
+func (t ColumnType) isDatetime() bool {
+	return t.Base == "datetime" || t.Base == "timestamp"
+}
+
+func (t ColumnType) currentTimestamp() string {
+	if t.Fsp > 0 {
+		return fmt.Sprintf("CURRENT_TIMESTAMP(%d)", t.Fsp)
+	}
+	return "CURRENT_TIMESTAMP"
+}
+
+// String returns the canonical SQL form of the column type.
+func (t ColumnType) String() string {
+	s := t.Base
+	switch {
+	case isEnumType(t.Base):
+		values := []string{}
+		for _, v := range t.Values {
+			values = append(values, quote(v))
+		}
+		s += fmt.Sprintf("(%s)", strings.Join(values, ","))
+	case isDecimalType(t.Base) && t.Precision > 0:
+		s += fmt.Sprintf("(%d,%d)", t.Precision, t.Scale)
+	case isTemporalType(t.Base) && t.Fsp > 0:
+		s += fmt.Sprintf("(%d)", t.Fsp)
+	case t.Length > 0:
+		s += fmt.Sprintf("(%d)", t.Length)
+	}
+	if t.Unsigned {
+		s += " unsigned"
+	}
+	if t.Zerofill {
+		s += " zerofill"
+	}
+	return s
+}
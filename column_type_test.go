@@ -0,0 +1,103 @@
+package migo_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/meta-closure/migo"
+)
+
+func TestParseColumnType(t *testing.T) {
+	type Case struct {
+		input          string
+		expectedType   migo.ColumnType
+		expectedString string
+		isSuccess      bool
+		spec           string
+	}
+
+	cases := []Case{
+		{
+			input:          "INT(11) UNSIGNED",
+			expectedType:   migo.ColumnType{Base: "int", Unsigned: true},
+			expectedString: "int unsigned",
+			isSuccess:      true,
+			spec:           "integer display width is ignored",
+		},
+		{
+			input:          "boolean",
+			expectedType:   migo.ColumnType{Base: "tinyint", Length: 1},
+			expectedString: "tinyint(1)",
+			isSuccess:      true,
+			spec:           "boolean alias",
+		},
+		{
+			input:          "int(5) zerofill",
+			expectedType:   migo.ColumnType{Base: "int", Length: 5, Unsigned: true, Zerofill: true},
+			expectedString: "int(5) unsigned zerofill",
+			isSuccess:      true,
+			spec:           "zerofill keeps display width",
+		},
+		{
+			input:          "numeric(8, 2)",
+			expectedType:   migo.ColumnType{Base: "decimal", Precision: 8, Scale: 2},
+			expectedString: "decimal(8,2)",
+			isSuccess:      true,
+			spec:           "decimal with precision and scale",
+		},
+		{
+			input:          "decimal",
+			expectedType:   migo.ColumnType{Base: "decimal", Precision: 10},
+			expectedString: "decimal(10,0)",
+			isSuccess:      true,
+			spec:           "decimal default precision",
+		},
+		{
+			input:          "datetime(6)",
+			expectedType:   migo.ColumnType{Base: "datetime", Fsp: 6},
+			expectedString: "datetime(6)",
+			isSuccess:      true,
+			spec:           "fractional seconds precision",
+		},
+		{
+			input:          "ENUM('a','it''s')",
+			expectedType:   migo.ColumnType{Base: "enum", Values: []string{"a", "it's"}},
+			expectedString: "enum('a','it''s')",
+			isSuccess:      true,
+			spec:           "enum values",
+		},
+		{
+			input:     "varchar(a)",
+			isSuccess: false,
+			spec:      "invalid length",
+		},
+		{
+			input:     "varchar(255",
+			isSuccess: false,
+			spec:      "unclosed parenthesis",
+		},
+	}
+
+	for _, c := range cases {
+		ct, err := migo.ParseColumnType(c.input)
+		if !c.isSuccess && err == nil {
+			t.Errorf("in %s, error is expected but null", c.spec)
+			continue
+		}
+		if c.isSuccess && err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if !reflect.DeepEqual(c.expectedType, ct) {
+			t.Errorf("in %s, expected type is %+v, but actual %+v", c.spec, c.expectedType, ct)
+		}
+		if c.expectedString != ct.String() {
+			t.Errorf("in %s, expected string is %s, but actual %s", c.spec, c.expectedString, ct.String())
+		}
+	}
+}
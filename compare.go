@@ -0,0 +1,526 @@
+package migo
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const primaryKeyIndexName = "PRIMARY"
+
+// introspectedColumn is a row of information_schema.COLUMNS.
+type introspectedColumn struct {
+	Table      string
+	Name       string
+	Type       string
+	Nullable   bool
+	Default    sql.NullString
+	Extra      string
+	Charset    sql.NullString
+	Collation  sql.NullString
+	Comment    string
+	Expression sql.NullString
+}
+
+// introspectedIndex is a row of information_schema.STATISTICS, which is
+// a column of the index.
+type introspectedIndex struct {
+	Table     string
+	Name      string
+	Column    string
+	NonUnique bool
+}
+
+// introspectedForeignKey is a row of information_schema.KEY_COLUMN_USAGE
+// joined with REFERENTIAL_CONSTRAINTS.
+type introspectedForeignKey struct {
+	Name         string
+	Table        string
+	Column       string
+	TargetTable  string
+	TargetColumn string
+	UpdateRule   string
+	DeleteRule   string
+}
+
+func (ic introspectedColumn) column() Column {
+	c := NewColumn(ic.Name)
+	c.Name = ic.Name
+	c.Type = ic.Type
+	c.NotNull = !ic.Nullable
+	c.Comment = ic.Comment
+	c.Charset = ic.Charset.String
+	c.Collation = ic.Collation.String
+
+	extra := strings.ToLower(ic.Extra)
+	c.AutoIncrement = strings.Contains(extra, "auto_increment")
+	c.AutoUpdate = strings.Contains(extra, "on update")
+	if ic.Expression.String != "" {
+		c.Expression = ic.Expression.String
+		c.Storage = storageVirtual
+		if strings.Contains(extra, "stored generated") {
+			c.Storage = storageStored
+		}
+		return c
+	}
+
+	c.Default = introspectedDefault(c, ic.Default, extra)
+	return c
+}
+
+// introspectedDefault reads the COLUMN_DEFAULT. The NULL default of a nullable
+// column is read as the NULL default, and the current timestamp of a datetime
+// column is left empty where migo gives it implicitly. MariaDB quotes the string
+// defaults and returns the NULL default as the literal NULL.
+func introspectedDefault(c Column, d sql.NullString, extra string) Default {
+	s := d.String
+	if !d.Valid || s == "NULL" {
+		if c.NotNull {
+			return Default{}
+		}
+		return Default{Type: DefaultNull}
+	}
+	if len(s) > 1 && strings.HasPrefix(s, "'") && strings.HasSuffix(s, "'") {
+		return Default{Type: DefaultString, Value: strings.Replace(s[1:len(s)-1], "''", "'", -1)}
+	}
+
+	t := c.columnType()
+	switch {
+	case isCurrentTimestamp(s):
+		if t.isDatetime() && (c.NotNull || c.AutoUpdate) {
+			return Default{}
+		}
+		return Default{Type: DefaultExpression, Value: s}
+	case strings.Contains(extra, "default_generated"):
+		return Default{Type: DefaultExpression, Value: strings.TrimSuffix(strings.TrimPrefix(s, "("), ")")}
+	case isIntegerType(t.Base) || isDecimalType(t.Base):
+		return Default{Type: DefaultNumber, Value: s}
+	}
+	return Default{Type: DefaultString, Value: s}
+}
+
+// newIntrospectedState builds the state from the rows of information_schema.
+// Ids of tables and columns are their names, as the database knows nothing
+// of the schema file. A unique index on a single column named after the
+// column is read as the unique column, which is how MySQL names it.
+func newIntrospectedState(cs []introspectedColumn, is []introspectedIndex, fks []introspectedForeignKey) (State, error) {
+	s := NewState()
+	tables := map[string]*Table{}
+	names := []string{}
+	for _, ic := range cs {
+		t, ok := tables[ic.Table]
+		if !ok {
+			t = NewTable(ic.Table)
+			t.Name = ic.Table
+			tables[ic.Table] = t
+			names = append(names, ic.Table)
+		}
+		t.Column = append(t.Column, ic.column())
+	}
+
+	keys := map[string]*Key{}
+	keyNames := []string{}
+	keyTables := map[string]string{}
+	unique := map[string]bool{}
+	for _, ii := range is {
+		t, ok := tables[ii.Table]
+		if !ok {
+			return s, fmt.Errorf("table %s of index %s is not found", ii.Table, ii.Name)
+		}
+		c, err := t.findColumnWithID(ii.Column)
+		if err != nil {
+			return s, fmt.Errorf("column %s of index %s is not found in table %s", ii.Column, ii.Name, ii.Table)
+		}
+		id := ii.Table + "." + ii.Name
+		k, ok := keys[id]
+		if !ok {
+			nk := NewKey(ii.Name)
+			k = &nk
+			keys[id] = k
+			keyNames = append(keyNames, id)
+			keyTables[id] = ii.Table
+		}
+		k.Target = append(k.Target, c)
+		unique[id] = !ii.NonUnique
+	}
+
+	for _, id := range keyNames {
+		k := keys[id]
+		t := tables[keyTables[id]]
+		switch {
+		case k.Name == primaryKeyIndexName:
+			t.PrimaryKey = append(t.PrimaryKey, *k)
+		case unique[id] && len(k.Target) == 1 && k.Target[0].Name == k.Name:
+			for i := range t.Column {
+				if t.Column[i].Name == k.Name {
+					t.Column[i].Unique = true
+				}
+			}
+		default:
+			t.Index = append(t.Index, *k)
+		}
+	}
+
+	for _, name := range names {
+		s.Tables = append(s.Tables, *tables[name])
+	}
+
+	for _, ifk := range fks {
+		st, err := s.findTableWithID(ifk.Table)
+		if err != nil {
+			return s, fmt.Errorf("table %s of foreign key %s is not found", ifk.Table, ifk.Name)
+		}
+		sc, err := st.findColumnWithID(ifk.Column)
+		if err != nil {
+			return s, fmt.Errorf("column %s of foreign key %s is not found", ifk.Column, ifk.Name)
+		}
+		tt, err := s.findTableWithID(ifk.TargetTable)
+		if err != nil {
+			return s, fmt.Errorf("target table %s of foreign key %s is not found", ifk.TargetTable, ifk.Name)
+		}
+		tc, err := tt.findColumnWithID(ifk.TargetColumn)
+		if err != nil {
+			return s, fmt.Errorf("target column %s of foreign key %s is not found", ifk.TargetColumn, ifk.Name)
+		}
+
+		fk := NewForeignKey(st, sc)
+		fk.Name = ifk.Name
+		fk.TargetTable, fk.TargetColumn = tt, tc
+		fk.UpdateCascade = ifk.UpdateRule == "CASCADE"
+		fk.DeleteCascade = ifk.DeleteRule == "CASCADE"
+		s.ForeignKey = append(s.ForeignKey, fk)
+	}
+
+	return s.Sort(), nil
+}
+
+// introspect reads the tables, indexes and foreign keys of the database from
+// information_schema.
+func (db DB) introspect() (State, error) {
+	mysql, err := sql.Open("mysql", db.FormatDSN())
+	if err != nil {
+		return State{}, NewConnectionError(err)
+	}
+	defer mysql.Close()
+
+	cs := []introspectedColumn{}
+	rows, err := mysql.Query(`SELECT c.TABLE_NAME, c.COLUMN_NAME, c.COLUMN_TYPE, c.IS_NULLABLE, c.COLUMN_DEFAULT, c.EXTRA,
+		c.CHARACTER_SET_NAME, c.COLLATION_NAME, c.COLUMN_COMMENT, c.GENERATION_EXPRESSION
+		FROM information_schema.COLUMNS c JOIN information_schema.TABLES t
+		ON c.TABLE_SCHEMA = t.TABLE_SCHEMA AND c.TABLE_NAME = t.TABLE_NAME
+		WHERE c.TABLE_SCHEMA = ? AND t.TABLE_TYPE = 'BASE TABLE'
+		ORDER BY c.TABLE_NAME, c.ORDINAL_POSITION`, db.DBName)
+	if err != nil {
+		return State{}, errors.Wrap(err, "reading columns")
+	}
+	for rows.Next() {
+		c, nullable := introspectedColumn{}, ""
+		if err := rows.Scan(&c.Table, &c.Name, &c.Type, &nullable, &c.Default, &c.Extra,
+			&c.Charset, &c.Collation, &c.Comment, &c.Expression); err != nil {
+			rows.Close()
+			return State{}, errors.Wrap(err, "reading columns")
+		}
+		c.Nullable = nullable == "YES"
+		cs = append(cs, c)
+	}
+	rows.Close()
+
+	is := []introspectedIndex{}
+	rows, err = mysql.Query(`SELECT TABLE_NAME, INDEX_NAME, COLUMN_NAME, NON_UNIQUE
+		FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX`, db.DBName)
+	if err != nil {
+		return State{}, errors.Wrap(err, "reading indexes")
+	}
+	for rows.Next() {
+		i := introspectedIndex{}
+		if err := rows.Scan(&i.Table, &i.Name, &i.Column, &i.NonUnique); err != nil {
+			rows.Close()
+			return State{}, errors.Wrap(err, "reading indexes")
+		}
+		is = append(is, i)
+	}
+	rows.Close()
+
+	fks := []introspectedForeignKey{}
+	rows, err = mysql.Query(`SELECT k.CONSTRAINT_NAME, k.TABLE_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_NAME,
+		k.REFERENCED_COLUMN_NAME, r.UPDATE_RULE, r.DELETE_RULE
+		FROM information_schema.KEY_COLUMN_USAGE k JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+		ON k.CONSTRAINT_SCHEMA = r.CONSTRAINT_SCHEMA AND k.CONSTRAINT_NAME = r.CONSTRAINT_NAME
+		WHERE k.TABLE_SCHEMA = ? AND k.REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY k.CONSTRAINT_NAME`, db.DBName)
+	if err != nil {
+		return State{}, errors.Wrap(err, "reading foreign keys")
+	}
+	for rows.Next() {
+		fk := introspectedForeignKey{}
+		if err := rows.Scan(&fk.Name, &fk.Table, &fk.Column, &fk.TargetTable,
+			&fk.TargetColumn, &fk.UpdateRule, &fk.DeleteRule); err != nil {
+			rows.Close()
+			return State{}, errors.Wrap(err, "reading foreign keys")
+		}
+		fks = append(fks, fk)
+	}
+	rows.Close()
+
+	s, err := newIntrospectedState(cs, is, fks)
+	if err != nil {
+		return s, err
+	}
+	s.DB = db
+	return s, nil
+}
+
+const (
+	differenceOnlyIn = "only in %s"
+	differenceDiffer = "differs"
+)
+
+// Difference is a table, column, index or foreign key which differs between
+// two states.
+type Difference struct {
+	Kind   string `json:"kind"`
+	Table  string `json:"table"`
+	Name   string `json:"name"`
+	Change string `json:"change"`
+}
+
+type Differences []Difference
+
+func (d Differences) Len() int {
+	return len(d)
+}
+
+func (d Differences) Less(i, j int) bool {
+	if d[i].Table != d[j].Table {
+		return d[i].Table < d[j].Table
+	}
+	if d[i].Kind != d[j].Kind {
+		return d[i].Kind < d[j].Kind
+	}
+	return d[i].Name < d[j].Name
+}
+
+func (d Differences) Swap(i, j int) {
+	d[i], d[j] = d[j], d[i]
+}
+
+func (d Difference) String() string {
+	if d.Kind == "table" {
+		return fmt.Sprintf("TABLE [%s]: %s", d.Table, d.Change)
+	}
+	return fmt.Sprintf("%s [%s] IN [%s]: %s", strings.ToUpper(d.Kind), d.Name, d.Table, d.Change)
+}
+
+// NewDifferences compares the tables of the states named by the environments
+// by their names.
+func NewDifferences(source, target State, sourceEnv, targetEnv string) Differences {
+	ds := Differences{}
+	add := func(kind, table, name, change string) {
+		ds = append(ds, Difference{Kind: kind, Table: table, Name: name, Change: change})
+	}
+
+	for _, st := range source.Tables {
+		tt, err := target.findTableWithID(st.Id)
+		if err != nil {
+			add("table", st.Name, st.Name, fmt.Sprintf(differenceOnlyIn, sourceEnv))
+			continue
+		}
+
+		for _, c := range st.Column {
+			tc, err := tt.findColumnWithID(c.Id)
+			switch {
+			case err != nil:
+				add("column", st.Name, c.Name, fmt.Sprintf(differenceOnlyIn, sourceEnv))
+			case !reflect.DeepEqual(c.normalize(), tc.normalize()):
+				add("column", st.Name, c.Name, differenceDiffer)
+			}
+		}
+		for _, c := range tt.Column {
+			if _, err := st.findColumnWithID(c.Id); err != nil {
+				add("column", st.Name, c.Name, fmt.Sprintf(differenceOnlyIn, targetEnv))
+			}
+		}
+
+		sk := append(append(Keys{}, st.PrimaryKey...), st.Index...)
+		tk := append(append(Keys{}, tt.PrimaryKey...), tt.Index...)
+		for _, d := range compareKeys(sk, tk, sourceEnv, targetEnv) {
+			add("index", st.Name, d.Name, d.Change)
+		}
+	}
+	for _, tt := range target.Tables {
+		if _, err := source.findTableWithID(tt.Id); err != nil {
+			add("table", tt.Name, tt.Name, fmt.Sprintf(differenceOnlyIn, targetEnv))
+		}
+	}
+
+	for _, fk := range source.ForeignKey {
+		tfk, ok := findForeignKeyWithName(target.ForeignKey, fk.Name)
+		switch {
+		case !ok:
+			add("foreign key", fk.SourceTable.Name, fk.Name, fmt.Sprintf(differenceOnlyIn, sourceEnv))
+		case NewAddForeignKey(fk).Query() != NewAddForeignKey(tfk).Query():
+			add("foreign key", fk.SourceTable.Name, fk.Name, differenceDiffer)
+		}
+	}
+	for _, fk := range target.ForeignKey {
+		if _, ok := findForeignKeyWithName(source.ForeignKey, fk.Name); !ok {
+			add("foreign key", fk.SourceTable.Name, fk.Name, fmt.Sprintf(differenceOnlyIn, targetEnv))
+		}
+	}
+
+	sort.Sort(ds)
+	return ds
+}
+
+func compareKeys(source, target Keys, sourceEnv, targetEnv string) Differences {
+	ds := Differences{}
+	for _, k := range source {
+		found := false
+		for _, tk := range target {
+			if tk.Name != k.Name {
+				continue
+			}
+			found = true
+			if updated, _ := k.isUpdatedFrom(tk); updated {
+				ds = append(ds, Difference{Name: k.Name, Change: differenceDiffer})
+			}
+		}
+		if !found {
+			ds = append(ds, Difference{Name: k.Name, Change: fmt.Sprintf(differenceOnlyIn, sourceEnv)})
+		}
+	}
+	for _, k := range target {
+		found := false
+		for _, sk := range source {
+			found = found || sk.Name == k.Name
+		}
+		if !found {
+			ds = append(ds, Difference{Name: k.Name, Change: fmt.Sprintf(differenceOnlyIn, targetEnv)})
+		}
+	}
+	return ds
+}
+
+func findForeignKeyWithName(fks ForeignKeys, name string) (ForeignKey, bool) {
+	for _, fk := range fks {
+		if fk.Name == name {
+			return fk, true
+		}
+	}
+	return ForeignKey{}, false
+}
+
+type comparison struct {
+	Differences Differences `json:"differences"`
+	plan
+}
+
+// WriteComparison prints the differences followed by the operations.
+func WriteComparison(w io.Writer, format string, ds Differences, ops Operations) error {
+	switch format {
+	case "", FormatText:
+		for _, d := range ds {
+			fmt.Fprintln(w, d.String())
+		}
+		fmt.Fprintln(w, "")
+	case FormatJSON:
+		return writeJSON(w, comparison{Differences: append(Differences{}, ds...), plan: newPlan(ops)})
+	case FormatSQL:
+		for _, d := range ds {
+			fmt.Fprintf(w, "-- %s\n", d.String())
+		}
+	}
+	return ops.Write(w, format)
+}
+
+// NewComparisonOperations returns the operations making the target match the
+// source, without the foreign keys which are dropped and added again as they are.
+func NewComparisonOperations(source, target State) (Operations, error) {
+	ops, err := NewOperations(target, source)
+	if err != nil {
+		return ops, err
+	}
+	return ops.skipUnchangedForeignKeys(), nil
+}
+
+// skipUnchangedForeignKeys removes the foreign keys which are dropped and added
+// again as they are, unless a table or column of them is changed.
+func (ops Operations) skipUnchangedForeignKeys() Operations {
+	changed := map[string]bool{}
+	for _, op := range ops.Operation {
+		switch o := op.(type) {
+		case DropTable:
+			changed[o.Table.Name] = true
+		case RenameTable:
+			changed[o.CurrentTable.Name] = true
+		case DropColumn:
+			changed[o.Table.Name+"."+o.Column.Name] = true
+		case UpdateColumn:
+			changed[o.Table.Name+"."+o.CurrentColumn.Name] = true
+		}
+	}
+	isChanged := func(fk ForeignKey) bool {
+		return changed[fk.SourceTable.Name] || changed[fk.TargetTable.Name] ||
+			changed[fk.SourceTable.Name+"."+fk.SourceColumn.Name] || changed[fk.TargetTable.Name+"."+fk.TargetColumn.Name]
+	}
+
+	dropped := map[string]bool{}
+	for _, op := range ops.Operation {
+		if o, ok := op.(DropForeignKey); ok && !isChanged(o.ForeignKey) {
+			dropped[NewAddForeignKey(o.ForeignKey).Query()] = true
+		}
+	}
+	kept := map[string]bool{}
+	for _, op := range ops.Operation {
+		if o, ok := op.(AddForeignKey); ok && dropped[o.Query()] {
+			kept[o.Query()] = true
+		}
+	}
+
+	skipped := []Operation{}
+	for _, op := range ops.Operation {
+		switch o := op.(type) {
+		case DropForeignKey:
+			if kept[NewAddForeignKey(o.ForeignKey).Query()] {
+				continue
+			}
+		case AddForeignKey:
+			if kept[o.Query()] {
+				continue
+			}
+		}
+		skipped = append(skipped, op)
+	}
+	ops.Operation = skipped
+	return ops
+}
+
+// Compare prints how the schema of the target environment differs from the
+// source environment, and the operations to make the target match the source.
+func Compare(op CompareOption, w io.Writer) error {
+	states := []State{}
+	for _, env := range []string{op.Source, op.Target} {
+		db, err := NewDB(op.ConfigFile, env)
+		if err != nil {
+			return err
+		}
+		s, err := db.introspect()
+		if err != nil {
+			return errors.Wrapf(err, "reading schema of %s", env)
+		}
+		states = append(states, s)
+	}
+	source, target := states[0], states[1]
+
+	ops, err := NewComparisonOperations(source, target)
+	if err != nil {
+		return errors.Wrap(err, "creating requests")
+	}
+	return WriteComparison(w, op.Format, NewDifferences(source, target, op.Source, op.Target), ops)
+}
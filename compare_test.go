@@ -0,0 +1,138 @@
+package migo_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/meta-closure/migo"
+)
+
+func TestNewDifferences(t *testing.T) {
+	type Input struct {
+		Source migo.State
+		Target migo.State
+	}
+
+	type Case struct {
+		input               Input
+		expectedDifferences migo.Differences
+		expectedOutput      string
+		spec                string
+	}
+
+	id := migo.Column{Id: "id", Name: "id", Type: "int(11)", NotNull: true}
+	name := migo.Column{Id: "name", Name: "name", Type: "varchar(255)"}
+	user := migo.Table{
+		Id:         "user",
+		Name:       "user",
+		Column:     migo.Columns{id, name},
+		PrimaryKey: migo.Keys{{Name: "PRIMARY", Target: migo.Columns{id}}},
+		Index:      migo.Keys{{Name: "idx_name", Target: migo.Columns{name}}},
+	}
+	post := migo.Table{
+		Id:     "post",
+		Name:   "post",
+		Column: migo.Columns{{Id: "user_id", Name: "user_id", Type: "int"}},
+	}
+	fk := migo.ForeignKey{
+		Name:         "fk_post_user",
+		SourceTable:  post,
+		SourceColumn: post.Column[0],
+		TargetTable:  user,
+		TargetColumn: id,
+	}
+
+	bigID := migo.Column{Id: "id", Name: "id", Type: "bigint(20)", NotNull: true}
+	bigUser := user
+	bigUser.Column = migo.Columns{bigID, name}
+	bigUser.PrimaryKey = migo.Keys{{Name: "PRIMARY", Target: migo.Columns{bigID}}}
+	bigFK := fk
+	bigFK.TargetTable, bigFK.TargetColumn = bigUser, bigID
+
+	changed := user
+	changed.Column = migo.Columns{id, {Id: "name", Name: "name", Type: "text"}}
+	changed.Index = nil
+
+	cases := []Case{
+		{
+			spec: "same schema",
+			input: Input{
+				Source: migo.State{Tables: migo.Tables{user, post}, ForeignKey: migo.ForeignKeys{fk}},
+				Target: migo.State{Tables: migo.Tables{user, post}, ForeignKey: migo.ForeignKeys{fk}},
+			},
+			expectedDifferences: migo.Differences{},
+			expectedOutput:      "\n",
+		},
+		{
+			spec: "foreign key on changed column",
+			input: Input{
+				Source: migo.State{Tables: migo.Tables{bigUser, post}, ForeignKey: migo.ForeignKeys{bigFK}},
+				Target: migo.State{Tables: migo.Tables{user, post}, ForeignKey: migo.ForeignKeys{fk}},
+			},
+			expectedDifferences: migo.Differences{
+				{Kind: "column", Table: "user", Name: "id", Change: "differs"},
+				{Kind: "index", Table: "user", Name: "PRIMARY", Change: "differs"},
+			},
+			expectedOutput: "COLUMN [id] IN [user]: differs\n" +
+				"INDEX [PRIMARY] IN [user]: differs\n\n" +
+				"DROP FOREIGN KEY FROM [post]: [user_id] => [id]: [id]\n" +
+				"DROP PRIMARY KEY PRIMARY IN user\n" +
+				"ADD PRIMARY KEY PRIMARY IN user\n" +
+				"CHANGE COLUMN [id] IN [user]\n" +
+				"ADD FOREIGN KEY FROM [user_id] IN [post] => [id] IN [user]\n",
+		},
+		{
+			spec: "table and foreign key only in source",
+			input: Input{
+				Source: migo.State{Tables: migo.Tables{user, post}, ForeignKey: migo.ForeignKeys{fk}},
+				Target: migo.State{Tables: migo.Tables{user}},
+			},
+			expectedDifferences: migo.Differences{
+				{Kind: "foreign key", Table: "post", Name: "fk_post_user", Change: "only in staging"},
+				{Kind: "table", Table: "post", Name: "post", Change: "only in staging"},
+			},
+			expectedOutput: "FOREIGN KEY [fk_post_user] IN [post]: only in staging\n" +
+				"TABLE [post]: only in staging\n\n" +
+				"ADD TABLE: [post]\n" +
+				"ADD FOREIGN KEY FROM [user_id] IN [post] => [id] IN [user]\n",
+		},
+		{
+			spec: "column and index differ",
+			input: Input{
+				Source: migo.State{Tables: migo.Tables{user}},
+				Target: migo.State{Tables: migo.Tables{changed}},
+			},
+			expectedDifferences: migo.Differences{
+				{Kind: "column", Table: "user", Name: "name", Change: "differs"},
+				{Kind: "index", Table: "user", Name: "idx_name", Change: "only in staging"},
+			},
+			expectedOutput: "COLUMN [name] IN [user]: differs\n" +
+				"INDEX [idx_name] IN [user]: only in staging\n\n" +
+				"ADD INDEX idx_name IN user\n" +
+				"CHANGE COLUMN [name] IN [user]\n",
+		},
+	}
+
+	for _, c := range cases {
+		ds := migo.NewDifferences(c.input.Source, c.input.Target, "staging", "production")
+		if !reflect.DeepEqual(ds, c.expectedDifferences) {
+			t.Errorf("in %s, expected differences are %v, but actual %v", c.spec, c.expectedDifferences, ds)
+			continue
+		}
+
+		ops, err := migo.NewComparisonOperations(c.input.Source, c.input.Target)
+		if err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+			continue
+		}
+		b := &bytes.Buffer{}
+		if err := migo.WriteComparison(b, migo.FormatText, ds, ops); err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+			continue
+		}
+		if b.String() != c.expectedOutput {
+			t.Errorf("in %s, expected output is %q, but actual %q", c.spec, c.expectedOutput, b.String())
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package migo
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	phaseExpanded = "expanded"
+
+	// backfillChunkSize is the rows updated by each UPDATE of a backfill.
+	backfillChunkSize = 1000
+)
+
+// Contract is a column change applied in the expand phase, whose old column
+// and sync triggers are left until the contract phase.
+type Contract struct {
+	TableId   string `json:"table_id"`
+	TableName string `json:"table_name"`
+	OldColumn Column `json:"old_column"`
+	NewColumn Column `json:"new_column"`
+	Phase     string `json:"phase"`
+}
+
+type Contracts []Contract
+
+func NewContract(t Table, old, new Column) Contract {
+	return Contract{
+		TableId:   t.Id,
+		TableName: t.Name,
+		OldColumn: old,
+		NewColumn: new,
+		Phase:     phaseExpanded,
+	}
+}
+
+// triggers are named after the table at the expand phase, so that a later
+// table rename does not lose them.
+func (c Contract) triggers() []Trigger {
+	o, n := c.OldColumn.Name, c.NewColumn.Name
+	return []Trigger{
+		{
+			Name:   fmt.Sprintf("%s_%s_%s_sync_insert", c.TableName, o, n),
+			Timing: "before",
+			Event:  "insert",
+			Body:   fmt.Sprintf("SET NEW.%s = COALESCE(NEW.%s, NEW.%s), NEW.%s = COALESCE(NEW.%s, NEW.%s)", n, n, o, o, o, n),
+		},
+		{
+			Name:   fmt.Sprintf("%s_%s_%s_sync_update", c.TableName, o, n),
+			Timing: "before",
+			Event:  "update",
+			Body: strings.Join([]string{
+				"BEGIN",
+				fmt.Sprintf("IF NOT (NEW.%s <=> OLD.%s) THEN SET NEW.%s = NEW.%s;", o, o, n, o),
+				fmt.Sprintf("ELSEIF NOT (NEW.%s <=> OLD.%s) THEN SET NEW.%s = NEW.%s;", n, n, o, n),
+				"END IF;",
+				"END",
+			}, " "),
+		},
+	}
+}
+
+// expandedColumn is the new column as it is added in the expand phase,
+// which is nullable until the old column is dropped.
+func (c Contract) expandedColumn() Column {
+	col := c.NewColumn
+	col.NotNull = false
+	col.Unique = false
+	return col
+}
+
+func (c Contract) expand(t Table) []Operation {
+	ops := []Operation{
+		NewAddColumn(t, c.expandedColumn()),
+	}
+	for _, tr := range c.triggers() {
+		ops = append(ops, NewCreateTrigger(t, tr))
+	}
+	return append(ops, NewBackfill(t, c.OldColumn, c.NewColumn))
+}
+
+func (c Contract) contract(t Table) []Operation {
+	ops := []Operation{}
+	for _, tr := range c.triggers() {
+		ops = append(ops, NewDropTrigger(t, tr))
+	}
+	ops = append(ops, NewDropColumn(t, c.OldColumn))
+	if c.expandedColumn() != c.NewColumn {
+		ops = append(ops, NewUpdateColumn(t, c.expandedColumn(), c.NewColumn))
+	}
+	return ops
+}
+
+// Expand replaces the column renames in ops with the expand phase steps,
+// which adds the new column, installs sync triggers and backfills it.
+func (ops Operations) Expand() Operations {
+	expanded := Operations{Warning: ops.Warning}
+	for _, op := range ops.Operation {
+		u, ok := op.(UpdateColumn)
+		if !ok || u.CurrentColumn.Name == u.NewColumn.Name {
+			expanded.Operation = append(expanded.Operation, op)
+			continue
+		}
+
+		c := NewContract(u.Table, u.CurrentColumn, u.NewColumn)
+		expanded.Operation = append(expanded.Operation, c.expand(u.Table)...)
+		expanded.Contract = append(expanded.Contract, c)
+	}
+	return expanded
+}
+
+// NewContractOperations returns the contract phase steps of the pending
+// contracts, looking up the tables as they are in the state.
+func NewContractOperations(cs Contracts, s State) (Operations, error) {
+	ops := Operations{}
+	for _, c := range cs {
+		t, err := s.findTableWithID(c.TableId)
+		if err != nil {
+			return ops, errors.Wrapf(err, "searching table %s of pending contract", c.TableId)
+		}
+		ops.Operation = append(ops.Operation, c.contract(t)...)
+	}
+	return ops, nil
+}
+
+// findPendingContracts returns the contracts whose table still exists in the state,
+// as dropping a table also drops its old columns and sync triggers.
+func (s State) findPendingContracts(cs Contracts) Contracts {
+	pending := Contracts{}
+	for _, c := range cs {
+		if _, err := s.findTableWithID(c.TableId); err == nil {
+			pending = append(pending, c)
+		}
+	}
+	return pending
+}
+
+type Backfill struct {
+	Table     Table
+	OldColumn Column
+	NewColumn Column
+}
+
+func NewBackfill(t Table, old, new Column) Backfill {
+	return Backfill{
+		Table:     t,
+		OldColumn: old,
+		NewColumn: new,
+	}
+}
+func (op Backfill) String() string {
+	if op.isChunked() {
+		return fmt.Sprintf("BACKFILL [%s] FROM [%s] IN [%s] BY %d ROWS", op.NewColumn.Name, op.OldColumn.Name, op.Table.Name, backfillChunkSize)
+	}
+	return fmt.Sprintf("BACKFILL [%s] FROM [%s] IN [%s]", op.NewColumn.Name, op.OldColumn.Name, op.Table.Name)
+}
+func (op Backfill) Query() string {
+	return fmt.Sprintf("UPDATE %s SET %s = %s", op.Table.Name, op.NewColumn.Name, op.OldColumn.Name)
+}
+
+// isChunked reports whether the backfill is split by the primary key, which
+// keeps the lock of each UPDATE short on a large table.
+func (op Backfill) isChunked() bool {
+	_, err := op.Table.chunkKey()
+	return err == nil
+}
+
+func (op Backfill) execChunks(db *sql.DB) error {
+	return execChunks(db, op.Table, fmt.Sprintf("%s WHERE %s", op.Query(), chunkPlaceholder), backfillChunkSize)
+}
+func (op Backfill) RollBack() string {
+	return fmt.Sprintf("UPDATE %s SET %s = NULL", op.Table.Name, op.NewColumn.Name)
+}
@@ -0,0 +1,259 @@
+package migo_test
+
+import (
+	"testing"
+
+	"github.com/meta-closure/migo"
+)
+
+func TestNewPhasedOperations(t *testing.T) {
+	type Input struct {
+		CurrentState migo.State
+		NewState     migo.State
+		Option       migo.MigrateOption
+	}
+
+	type Case struct {
+		input             Input
+		expectedQueries   []string
+		expectedSummaries []string
+		expectedContracts int
+		expectedDataSteps []string
+		isSuccess         bool
+		spec              string
+	}
+
+	oldColumn := migo.Column{Id: "#/definitions/user/properties/name", Name: "name", Type: "varchar(255)", NotNull: true}
+	newColumn := migo.Column{Id: "#/definitions/user/properties/name", Name: "full_name", Type: "varchar(255)", NotNull: true}
+	current := migo.State{
+		Tables: []migo.Table{
+			{Id: "#/definitions/user", Name: "user", Column: []migo.Column{oldColumn}},
+		},
+	}
+	new := migo.State{
+		Tables: []migo.Table{
+			{Id: "#/definitions/user", Name: "user", Column: []migo.Column{newColumn}},
+		},
+	}
+	expanded := new
+
+	id := migo.Column{Id: "#/definitions/user/properties/id", Name: "id", Type: "int", NotNull: true}
+	pk := migo.Keys{{Name: "user_pk", Target: migo.Columns{id}}}
+	currentWithKey := migo.State{
+		Tables: []migo.Table{
+			{Id: "#/definitions/user", Name: "user", Column: []migo.Column{id, oldColumn}, PrimaryKey: pk},
+		},
+	}
+	newWithKey := migo.State{
+		Tables: []migo.Table{
+			{Id: "#/definitions/user", Name: "user", Column: []migo.Column{id, newColumn}, PrimaryKey: pk},
+		},
+	}
+	uuid := migo.Column{Id: "#/definitions/user/properties/id", Name: "id", Type: "char(36)", NotNull: true}
+	uuidKey := migo.Keys{{Name: "user_pk", Target: migo.Columns{uuid}}}
+	currentWithUUIDKey := migo.State{
+		Tables: []migo.Table{
+			{Id: "#/definitions/user", Name: "user", Column: []migo.Column{uuid, oldColumn}, PrimaryKey: uuidKey},
+		},
+	}
+	newWithUUIDKey := migo.State{
+		Tables: []migo.Table{
+			{Id: "#/definitions/user", Name: "user", Column: []migo.Column{uuid, newColumn}, PrimaryKey: uuidKey},
+		},
+	}
+	expanded.Contract = migo.Contracts{
+		migo.NewContract(new.Tables[0], oldColumn, newColumn),
+	}
+
+	cases := []Case{
+		{
+			spec: "rename column without expand/contract",
+			input: Input{
+				CurrentState: current,
+				NewState:     new,
+			},
+			expectedQueries: []string{
+				"ALTER TABLE user CHANGE COLUMN name full_name varchar(255) NOT NULL",
+			},
+			isSuccess: true,
+		},
+		{
+			spec: "rename column in expand phase",
+			input: Input{
+				CurrentState: current,
+				NewState:     new,
+				Option:       migo.MigrateOption{Expand: true},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE user ADD COLUMN full_name varchar(255)",
+				"CREATE TRIGGER user_name_full_name_sync_insert BEFORE INSERT ON user FOR EACH ROW SET NEW.full_name = COALESCE(NEW.full_name, NEW.name), NEW.name = COALESCE(NEW.name, NEW.full_name)",
+				"CREATE TRIGGER user_name_full_name_sync_update BEFORE UPDATE ON user FOR EACH ROW BEGIN IF NOT (NEW.name <=> OLD.name) THEN SET NEW.full_name = NEW.name; ELSEIF NOT (NEW.full_name <=> OLD.full_name) THEN SET NEW.name = NEW.full_name; END IF; END",
+				"UPDATE user SET full_name = name",
+			},
+			expectedContracts: 1,
+			isSuccess:         true,
+		},
+		{
+			spec: "backfill by primary key in expand phase",
+			input: Input{
+				CurrentState: currentWithKey,
+				NewState:     newWithKey,
+				Option:       migo.MigrateOption{Expand: true},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE user ADD COLUMN full_name varchar(255)",
+				"CREATE TRIGGER user_name_full_name_sync_insert BEFORE INSERT ON user FOR EACH ROW SET NEW.full_name = COALESCE(NEW.full_name, NEW.name), NEW.name = COALESCE(NEW.name, NEW.full_name)",
+				"CREATE TRIGGER user_name_full_name_sync_update BEFORE UPDATE ON user FOR EACH ROW BEGIN IF NOT (NEW.name <=> OLD.name) THEN SET NEW.full_name = NEW.name; ELSEIF NOT (NEW.full_name <=> OLD.full_name) THEN SET NEW.name = NEW.full_name; END IF; END",
+				"UPDATE user SET full_name = name",
+			},
+			expectedSummaries: []string{
+				"ADD COLUMN [full_name] IN [user]",
+				"ADD TRIGGER user_name_full_name_sync_insert IN user",
+				"ADD TRIGGER user_name_full_name_sync_update IN user",
+				"BACKFILL [full_name] FROM [name] IN [user] BY 1000 ROWS",
+			},
+			expectedContracts: 1,
+			isSuccess:         true,
+		},
+		{
+			spec: "backfill at once by non-integer primary key in expand phase",
+			input: Input{
+				CurrentState: currentWithUUIDKey,
+				NewState:     newWithUUIDKey,
+				Option:       migo.MigrateOption{Expand: true},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE user ADD COLUMN full_name varchar(255)",
+				"CREATE TRIGGER user_name_full_name_sync_insert BEFORE INSERT ON user FOR EACH ROW SET NEW.full_name = COALESCE(NEW.full_name, NEW.name), NEW.name = COALESCE(NEW.name, NEW.full_name)",
+				"CREATE TRIGGER user_name_full_name_sync_update BEFORE UPDATE ON user FOR EACH ROW BEGIN IF NOT (NEW.name <=> OLD.name) THEN SET NEW.full_name = NEW.name; ELSEIF NOT (NEW.full_name <=> OLD.full_name) THEN SET NEW.name = NEW.full_name; END IF; END",
+				"UPDATE user SET full_name = name",
+			},
+			expectedSummaries: []string{
+				"ADD COLUMN [full_name] IN [user]",
+				"ADD TRIGGER user_name_full_name_sync_insert IN user",
+				"ADD TRIGGER user_name_full_name_sync_update IN user",
+				"BACKFILL [full_name] FROM [name] IN [user]",
+			},
+			expectedContracts: 1,
+			isSuccess:         true,
+		},
+		{
+			spec: "keep pending contract",
+			input: Input{
+				CurrentState: expanded,
+				NewState:     new,
+				Option:       migo.MigrateOption{Expand: true},
+			},
+			expectedQueries:   []string{},
+			expectedContracts: 1,
+			isSuccess:         true,
+		},
+		{
+			spec: "apply pending contract",
+			input: Input{
+				CurrentState: expanded,
+				NewState:     new,
+				Option:       migo.MigrateOption{Contract: true},
+			},
+			expectedQueries: []string{
+				"DROP TRIGGER user_name_full_name_sync_insert",
+				"DROP TRIGGER user_name_full_name_sync_update",
+				"ALTER TABLE user DROP COLUMN name",
+				"ALTER TABLE user CHANGE COLUMN full_name full_name varchar(255) NOT NULL",
+			},
+			isSuccess: true,
+		},
+		{
+			spec: "record applied data steps in new state",
+			input: Input{
+				CurrentState: migo.State{
+					Tables: []migo.Table{
+						{Id: "#/definitions/user", Name: "user", Column: []migo.Column{oldColumn}},
+					},
+					AppliedDataSteps: []string{"#/definitions/user/table/data/fill_name"},
+				},
+				NewState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:     "#/definitions/user",
+							Name:   "user",
+							Column: []migo.Column{oldColumn, {Id: "#/definitions/user/properties/nickname", Name: "nickname", Type: "varchar(255)"}},
+							Data: []migo.DataStep{
+								{Name: "fill_name", Timing: "after", Column: "#/definitions/user/properties/name", SQL: "UPDATE user SET name = ''"},
+								{Name: "fill_nickname", Timing: "after", Column: "#/definitions/user/properties/nickname", SQL: "UPDATE user SET nickname = name"},
+							},
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE user ADD COLUMN nickname varchar(255)",
+				"UPDATE user SET nickname = name",
+			},
+			expectedDataSteps: []string{
+				"#/definitions/user/table/data/fill_name",
+				"#/definitions/user/table/data/fill_nickname",
+			},
+			isSuccess: true,
+		},
+		{
+			spec: "drop pending contract with its table",
+			input: Input{
+				CurrentState: expanded,
+				NewState:     migo.State{},
+				Option:       migo.MigrateOption{Contract: true},
+			},
+			expectedQueries: []string{
+				"DROP TABLE user",
+			},
+			isSuccess: true,
+		},
+	}
+
+	for _, c := range cases {
+		new := c.input.NewState
+		op, err := migo.NewPhasedOperations(c.input.CurrentState, &new, c.input.Option)
+		if !c.isSuccess && err == nil {
+			t.Errorf("in %s, error is expected but null", c.spec)
+			continue
+		}
+		if c.isSuccess && err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if len(op.Operation) != len(c.expectedQueries) {
+			t.Errorf("in %s, expected query length is %d, but actual %d", c.spec, len(c.expectedQueries), len(op.Operation))
+			continue
+		}
+		for i := range op.Operation {
+			if c.expectedQueries[i] != op.Operation[i].Query() {
+				t.Errorf("in %s, expected query is %s, but actual %s", c.spec, c.expectedQueries[i], op.Operation[i].Query())
+			}
+		}
+
+		for i := range c.expectedSummaries {
+			if c.expectedSummaries[i] != op.Operation[i].String() {
+				t.Errorf("in %s, expected summary is %s, but actual %s", c.spec, c.expectedSummaries[i], op.Operation[i].String())
+			}
+		}
+
+		if len(new.Contract) != c.expectedContracts {
+			t.Errorf("in %s, expected pending contracts are %d, but actual %d", c.spec, c.expectedContracts, len(new.Contract))
+		}
+
+		if len(new.AppliedDataSteps) != len(c.expectedDataSteps) {
+			t.Errorf("in %s, expected applied data steps are %v, but actual %v", c.spec, c.expectedDataSteps, new.AppliedDataSteps)
+			continue
+		}
+		for i := range new.AppliedDataSteps {
+			if c.expectedDataSteps[i] != new.AppliedDataSteps[i] {
+				t.Errorf("in %s, expected applied data step is %s, but actual %s", c.spec, c.expectedDataSteps[i], new.AppliedDataSteps[i])
+			}
+		}
+	}
+}
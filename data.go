@@ -0,0 +1,363 @@
+package migo
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// chunkPlaceholder in the SQL of a chunked data step is replaced by a range of the primary key.
+const chunkPlaceholder = "{{chunk}}"
+
+// DataStep is SQL declared in a table block, which is run before or after
+// the change of the table or one of its columns.
+type DataStep struct {
+	Name      string `json:"name"`
+	Timing    string `json:"timing"`
+	Column    string `json:"column"`
+	SQL       string `json:"sql"`
+	Rollback  string `json:"rollback"`
+	ChunkSize int    `json:"chunk_size"`
+}
+
+type DataSteps []DataStep
+
+func (d DataSteps) Len() int {
+	return len(d)
+}
+
+func (d DataSteps) Less(i, j int) bool {
+	return d[i].Name < d[j].Name
+}
+
+func (d DataSteps) Swap(i, j int) {
+	d[i], d[j] = d[j], d[i]
+}
+
+func NewDataStep(name string) DataStep {
+	return DataStep{Name: name, Timing: "after"}
+}
+
+func (d *DataStep) read(t Table, i interface{}) error {
+	m, ok := i.(map[string]interface{})
+	if !ok {
+		return errors.New("fail to convert type to map[string]interface{}")
+	}
+
+	for _, f := range []struct {
+		key string
+		dst *string
+	}{
+		{"timing", &d.Timing},
+		{"column", &d.Column},
+		{"sql", &d.SQL},
+		{"rollback", &d.Rollback},
+	} {
+		if m[f.key] == nil {
+			continue
+		}
+		s, ok := m[f.key].(string)
+		if !ok {
+			return fmt.Errorf("fail to convert string type from %v", m[f.key])
+		}
+		*f.dst = s
+	}
+	if d.SQL == "" {
+		return errors.New("sql is not found")
+	}
+
+	switch strings.ToUpper(d.Timing) {
+	case "BEFORE", "AFTER":
+	default:
+		return fmt.Errorf("timing %s is invalid", d.Timing)
+	}
+	if m["chunk_size"] == nil {
+		return nil
+	}
+	n, ok := m["chunk_size"].(float64)
+	if !ok || n < 1 {
+		return fmt.Errorf("chunk_size %v is invalid", m["chunk_size"])
+	}
+	d.ChunkSize = int(n)
+	if !strings.Contains(d.SQL, chunkPlaceholder) {
+		return fmt.Errorf("chunked sql must contain %s", chunkPlaceholder)
+	}
+	if _, err := t.chunkKey(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d DataStep) isBefore() bool {
+	return strings.ToUpper(d.Timing) == "BEFORE"
+}
+
+func (d DataStep) isChunked() bool {
+	return d.ChunkSize > 0
+}
+
+// chunkKey returns the primary key column used to split a chunked data step.
+// The chunks are ranges of the key, so it must be an integer column.
+func (t Table) chunkKey() (Column, error) {
+	if len(t.PrimaryKey) != 1 || len(t.PrimaryKey[0].Target) != 1 {
+		return Column{}, fmt.Errorf("chunked data step needs the single column primary key in table %s", t.Name)
+	}
+	k := t.PrimaryKey[0].Target[0]
+	if !isIntegerType(k.columnType().Base) {
+		return Column{}, fmt.Errorf("chunked data step needs the integer primary key in table %s, but %s is %s", t.Name, k.Name, k.Type)
+	}
+	return k, nil
+}
+
+func (t Table) findDataSteps(m map[string]interface{}) ([]DataStep, error) {
+	if m["data"] == nil {
+		return nil, nil
+	}
+	ds, ok := m["data"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("fail to convert type to map[string]interface{}")
+	}
+
+	steps := []DataStep{}
+	for k, v := range ds {
+		d := NewDataStep(k)
+		if err := d.read(t, v); err != nil {
+			return nil, errors.Wrapf(err, "reading data step %s", k)
+		}
+		steps = append(steps, d)
+	}
+	return steps, nil
+}
+
+// isTargetOf returns whether the operation changes the table, or the column
+// of the data step if it is declared.
+func (d DataStep) isTargetOf(t Table, op Operation) bool {
+	var table Table
+	cs := []Column{}
+	switch o := op.(type) {
+	case CreateTable:
+		table = o.Table
+		cs = o.Table.Column
+	case RenameTable:
+		table = o.NewTable
+	case AddColumn:
+		table = o.Table
+		cs = append(cs, o.Column)
+	case DropColumn:
+		table = o.Table
+		cs = append(cs, o.Column)
+	case UpdateColumn:
+		table = o.Table
+		cs = append(cs, o.CurrentColumn, o.NewColumn)
+	case AddIndex:
+		table = o.Table
+	case DropIndex:
+		table = o.Table
+	case AddPrimaryKey:
+		table = o.Table
+	case DropPrimaryKey:
+		table = o.Table
+	case AddCheck:
+		table = o.Table
+	case DropCheck:
+		table = o.Table
+	default:
+		return false
+	}
+
+	if !t.isSameAs(table) {
+		return false
+	}
+	if d.Column == "" {
+		return true
+	}
+	for _, c := range cs {
+		if d.isStepOf(t, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStepOf returns whether the column is the target column of the data step. The
+// target column may be only in the current table, when the step is run before it is dropped.
+func (d DataStep) isStepOf(t Table, c Column) bool {
+	if c.Id == d.Column {
+		return true
+	}
+	target, err := t.findColumnWithID(d.Column)
+	return err == nil && target.isSameAs(c)
+}
+
+// pointer returns the JSON pointer of the data step in the schema, which is
+// recorded in the state once the step is applied.
+func (d DataStep) pointer(t Table) string {
+	return fmt.Sprintf("%s/table/data/%s", t.Id, d.Name)
+}
+
+// relaxNotNull splits the operation adding or changing the target column to NOT NULL
+// into the operation making it nullable, and the one making it NOT NULL after the data
+// step fills the column. The first one is nil when the column is nullable already.
+func (d DataStep) relaxNotNull(t Table, op Operation) (Operation, Operation, bool) {
+	switch o := op.(type) {
+	case AddColumn:
+		if !o.Column.NotNull || !d.isStepOf(t, o.Column) {
+			return nil, nil, false
+		}
+		nullable := o.Column
+		nullable.NotNull = false
+		return NewAddColumn(o.Table, nullable), NewUpdateColumn(o.Table, nullable, o.Column), true
+	case UpdateColumn:
+		if !o.NewColumn.NotNull || o.CurrentColumn.NotNull || !d.isStepOf(t, o.NewColumn) {
+			return nil, nil, false
+		}
+		nullable := o.NewColumn
+		nullable.NotNull = false
+		if ok, err := nullable.isUpdatedFrom(o.CurrentColumn); err == nil && !ok {
+			return nil, NewUpdateColumn(o.Table, o.CurrentColumn, o.NewColumn), true
+		}
+		return NewUpdateColumn(o.Table, o.CurrentColumn, nullable), NewUpdateColumn(o.Table, nullable, o.NewColumn), true
+	}
+	return nil, nil, false
+}
+
+// InsertDataSteps puts the data steps of the tables in the new state before the first
+// or after the last operation changing their target. Data steps without any
+// change of their target, or applied in the current state, are not run. The column
+// made NOT NULL by the operations is nullable until the data step after them is run.
+func (ops *Operations) InsertDataSteps(current, new State) {
+	applied := map[string]bool{}
+	for _, p := range current.AppliedDataSteps {
+		applied[p] = true
+	}
+
+	for _, t := range new.Tables {
+		for _, d := range t.Data {
+			if applied[d.pointer(t)] {
+				continue
+			}
+			first, last := -1, -1
+			for i, op := range ops.Operation {
+				if !d.isTargetOf(t, op) {
+					continue
+				}
+				if first < 0 {
+					first = i
+				}
+				last = i
+			}
+			if first < 0 {
+				continue
+			}
+
+			if d.isBefore() {
+				inserted := append([]Operation{}, ops.Operation[:first]...)
+				inserted = append(inserted, NewRunDataStep(t, d))
+				ops.Operation = append(inserted, ops.Operation[first:]...)
+				continue
+			}
+
+			inserted := []Operation{}
+			after := []Operation{NewRunDataStep(t, d)}
+			for i, op := range ops.Operation[:last+1] {
+				if i < first {
+					inserted = append(inserted, op)
+					continue
+				}
+				relaxed, tightened, ok := d.relaxNotNull(t, op)
+				if !ok {
+					inserted = append(inserted, op)
+					continue
+				}
+				if relaxed != nil {
+					inserted = append(inserted, relaxed)
+				}
+				after = append(after, tightened)
+			}
+			inserted = append(inserted, after...)
+			ops.Operation = append(inserted, ops.Operation[last+1:]...)
+		}
+	}
+}
+
+// appliedDataSteps returns the pointers of the data steps run by the operations.
+func (ops Operations) appliedDataSteps() []string {
+	ps := []string{}
+	for _, op := range ops.Operation {
+		if o, ok := op.(RunDataStep); ok {
+			ps = append(ps, o.DataStep.pointer(o.Table))
+		}
+	}
+	return ps
+}
+
+type RunDataStep struct {
+	Table    Table
+	DataStep DataStep
+}
+
+func NewRunDataStep(t Table, d DataStep) RunDataStep {
+	return RunDataStep{
+		Table:    t,
+		DataStep: d,
+	}
+}
+func (op RunDataStep) String() string {
+	if op.DataStep.isChunked() {
+		return fmt.Sprintf("RUN DATA STEP [%s] IN [%s] BY %d ROWS", op.DataStep.Name, op.Table.Name, op.DataStep.ChunkSize)
+	}
+	return fmt.Sprintf("RUN DATA STEP [%s] IN [%s]", op.DataStep.Name, op.Table.Name)
+}
+
+// Query returns the SQL of the data step run at once over the table.
+func (op RunDataStep) Query() string {
+	return strings.Replace(op.DataStep.SQL, chunkPlaceholder, "1 = 1", -1)
+}
+func (op RunDataStep) RollBack() string {
+	return op.DataStep.Rollback
+}
+
+func (op RunDataStep) isChunked() bool {
+	return op.DataStep.isChunked()
+}
+
+func (op RunDataStep) execChunks(db *sql.DB) error {
+	return execChunks(db, op.Table, op.DataStep.SQL, op.DataStep.ChunkSize)
+}
+
+// chunkedOperation is an operation run over the ranges of the primary key
+// by execChunks, instead of its Query at once.
+type chunkedOperation interface {
+	isChunked() bool
+	execChunks(db *sql.DB) error
+}
+
+// execChunks runs the SQL for each range of size rows of the primary key of
+// the table, replacing the chunk placeholder by the range.
+func execChunks(db *sql.DB, t Table, query string, size int) error {
+	k, err := t.chunkKey()
+	if err != nil {
+		return err
+	}
+
+	var min, max sql.NullInt64
+	q := fmt.Sprintf("SELECT MIN(%s), MAX(%s) FROM %s", k.Name, k.Name, t.Name)
+	if err := db.QueryRow(q).Scan(&min, &max); err != nil {
+		return errors.Wrapf(err, "Query: %s", q)
+	}
+	if !min.Valid {
+		return nil
+	}
+
+	n := int64(size)
+	for from := min.Int64; from <= max.Int64; from += n {
+		chunk := fmt.Sprintf("%s BETWEEN %d AND %d", k.Name, from, from+n-1)
+		q := strings.Replace(query, chunkPlaceholder, chunk, -1)
+		if _, err := db.Exec(q); err != nil {
+			return errors.Wrapf(err, "Query: %s", q)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package migo
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// NewDDLOperations returns the operations creating the state from an empty
+// database. The tables come first in the order of their Ids with their
+// triggers, followed by the foreign keys, views, routines and events, so the
+// same state always gives the same script.
+func NewDDLOperations(s State) (Operations, error) {
+	s = s.Sort()
+	ops := Operations{}
+	if err := ops.CreateTables(s.Tables); err != nil {
+		return ops, err
+	}
+	for _, fk := range s.ForeignKey {
+		ops.Operation = append(ops.Operation, NewAddForeignKey(fk))
+	}
+	if err := ops.CreateViews(State{}, s, nil); err != nil {
+		return ops, err
+	}
+	if err := ops.CreateRoutines(State{}, s); err != nil {
+		return ops, err
+	}
+	return ops, nil
+}
+
+// DDL prints the SQL script creating the schema read from the schema file, or
+// from the state file if it is given.
+func DDL(op DDLOption, w io.Writer) error {
+	var s State
+	var err error
+	if op.StateFile != "" {
+		s, err = NewStateFromYAML(op.StateFile)
+		if err != nil {
+			return errors.Wrap(err, "State YAML file parse error")
+		}
+	} else {
+		s, err = readSchemaState(op.Migrate)
+		if err != nil {
+			return err
+		}
+	}
+
+	ops, err := NewDDLOperations(s)
+	if err != nil {
+		return errors.Wrap(err, "creating requests")
+	}
+	return ops.Write(w, FormatSQL)
+}
@@ -0,0 +1,103 @@
+package migo_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/meta-closure/migo"
+)
+
+func TestDDL(t *testing.T) {
+	type Case struct {
+		input          migo.DDLOption
+		expectedOutput string
+		isSuccess      bool
+		spec           string
+	}
+
+	cases := []Case{
+		{
+			input: migo.DDLOption{
+				Migrate: migo.MigrateOption{
+					SchemaFile: "./test/parse_test_fk.yml",
+					FormatType: "yaml",
+				},
+			},
+			expectedOutput: "CREATE TABLE test2 (source_column source_type UNIQUE DEFAULT 'default_test')ENGINE=innoDB;\n" +
+				"CREATE TABLE test1 (target_column target_type UNIQUE DEFAULT 'default_test',PRIMARY KEY test_pk (target_column))ENGINE=innoDB;\n" +
+				"ALTER TABLE test2 ADD CONSTRAINT fk_test FOREIGN KEY (source_column) REFERENCES test1 (target_column) ON DELETE CASCADE;\n",
+			isSuccess: true,
+			spec:      "tables followed by foreign key",
+		},
+		{
+			input: migo.DDLOption{
+				Migrate: migo.MigrateOption{
+					SchemaFile: "./test/diff_test_new.yml",
+					FormatType: "yaml",
+				},
+			},
+			expectedOutput: "CREATE TABLE user (id int,name varchar(255))ENGINE=innoDB;\n",
+			isSuccess:      true,
+			spec:           "columns in order",
+		},
+		{
+			input: migo.DDLOption{
+				Migrate: migo.MigrateOption{
+					SchemaFile: "./test/parse_test_trigger.yml",
+					FormatType: "yaml",
+				},
+			},
+			expectedOutput: "CREATE TABLE test (updated_by varchar(255))ENGINE=innoDB;\n" +
+				"DELIMITER ;;\n" +
+				"CREATE TRIGGER test_audit BEFORE UPDATE ON test FOR EACH ROW SET NEW.updated_by = CURRENT_USER();;\n" +
+				"DELIMITER ;\n",
+			isSuccess: true,
+			spec:      "trigger in delimiter block",
+		},
+		{
+			input: migo.DDLOption{
+				Migrate: migo.MigrateOption{
+					SchemaFile: "./test/parse_test_routine.yml",
+					FormatType: "yaml",
+				},
+			},
+			expectedOutput: "CREATE TABLE user (created_at datetime)ENGINE=innoDB;\n" +
+				"DELIMITER ;;\n" +
+				"CREATE FUNCTION user_count(since DATETIME) RETURNS INT READS SQL DATA RETURN (SELECT COUNT(*) FROM user WHERE created_at > since);;\n" +
+				"DELIMITER ;\n" +
+				"DELIMITER ;;\n" +
+				"CREATE EVENT purge_session ON SCHEDULE EVERY 1 DAY DO DELETE FROM session WHERE expired_at < NOW();;\n" +
+				"DELIMITER ;\n",
+			isSuccess: true,
+			spec:      "routine and event in delimiter blocks",
+		},
+		{
+			input: migo.DDLOption{
+				StateFile: "./test/not_found.yml",
+			},
+			isSuccess: false,
+			spec:      "state file not found",
+		},
+	}
+
+	for _, c := range cases {
+		b := &bytes.Buffer{}
+		err := migo.DDL(c.input, b)
+		if !c.isSuccess && err == nil {
+			t.Errorf("in %s, error is expected but null", c.spec)
+			continue
+		}
+		if c.isSuccess && err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if b.String() != c.expectedOutput {
+			t.Errorf("in %s, expected output is %q, but actual %q", c.spec, c.expectedOutput, b.String())
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package migo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type DefaultType int
+
+const (
+	DefaultNone DefaultType = iota
+	DefaultString
+	DefaultNumber
+	DefaultBoolean
+	DefaultNull
+	DefaultExpression
+)
+
+// Default is a column default value. In the schema it is declared as a
+// scalar literal, `null`, or `{expression: UUID()}`.
+type Default struct {
+	Type  DefaultType
+	Value string
+}
+
+func NewDefault(i interface{}) (Default, error) {
+	switch v := i.(type) {
+	case nil:
+		return Default{Type: DefaultNull}, nil
+	case string:
+		return Default{Type: DefaultString, Value: v}, nil
+	case float64:
+		return Default{Type: DefaultNumber, Value: number(v)}, nil
+	case bool:
+		return Default{Type: DefaultBoolean, Value: strconv.FormatBool(v)}, nil
+	case map[string]interface{}:
+		if e, ok := v["expression"]; ok {
+			s, ok := e.(string)
+			if !ok {
+				return Default{}, fmt.Errorf("fail to convert string type from %v", e)
+			}
+			return Default{Type: DefaultExpression, Value: s}, nil
+		}
+		if l, ok := v["literal"]; ok {
+			return NewDefault(l)
+		}
+		if n, ok := v["null"].(bool); ok && n {
+			return Default{Type: DefaultNull}, nil
+		}
+	}
+	return Default{}, fmt.Errorf("fail to read default value from %v", i)
+}
+
+func (d Default) isEmpty() bool {
+	return d.Type == DefaultNone
+}
+
+func isCurrentTimestamp(s string) bool {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	return strings.HasPrefix(s, "CURRENT_TIMESTAMP") || strings.HasPrefix(s, "NOW(")
+}
+
+func (d Default) query() string {
+	switch d.Type {
+	case DefaultString:
+		return quote(d.Value)
+	case DefaultNumber:
+		return d.Value
+	case DefaultBoolean:
+		return strings.ToUpper(d.Value)
+	case DefaultNull:
+		return "NULL"
+	case DefaultExpression:
+		if isCurrentTimestamp(d.Value) {
+			return d.Value
+		}
+		return fmt.Sprintf("(%s)", d.Value)
+	}
+	return ""
+}
+
+func (d *Default) UnmarshalJSON(b []byte) error {
+	var i interface{}
+	if err := json.Unmarshal(b, &i); err != nil {
+		return errors.Wrap(err, "convert to default")
+	}
+	// an empty string is how a missing default has been stored in state files
+	if s, ok := i.(string); ok && s == "" {
+		*d = Default{}
+		return nil
+	}
+
+	v, err := NewDefault(i)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func (d Default) MarshalJSON() ([]byte, error) {
+	switch d.Type {
+	case DefaultNone:
+		return json.Marshal("")
+	case DefaultString:
+		// an empty literal is kept apart from the empty string of no default
+		if d.Value == "" {
+			return json.Marshal(map[string]string{"literal": ""})
+		}
+		return json.Marshal(d.Value)
+	case DefaultNumber, DefaultBoolean:
+		return []byte(d.Value), nil
+	case DefaultNull:
+		return []byte("null"), nil
+	case DefaultExpression:
+		return json.Marshal(map[string]string{"expression": d.Value})
+	}
+	return nil, fmt.Errorf("unknown default type %d", d.Type)
+}
@@ -0,0 +1,158 @@
+package migo
+
+import (
+	"archive/tar"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// schemaFileOption returns the option to read the schema file, which format is
+// told from the extension.
+func schemaFileOption(filePath, env string, op SchemaOption) MigrateOption {
+	m := MigrateOption{Environment: env, Schema: op}
+	if filepath.Ext(filePath) == ".json" {
+		m.SetJSONFormatSchema(filePath)
+	} else {
+		m.SetYAMLFormatSchema(filePath)
+	}
+	return m
+}
+
+// readSchemaState builds the state from the schema file without the database.
+func readSchemaState(op MigrateOption) (State, error) {
+	h, err := ReadSchema(op)
+	if err != nil {
+		return State{}, errors.Wrapf(err, "parsing hyper-schema from %s", op.SchemaFile)
+	}
+	s, err := NewStateFromSchema(h, op.Schema)
+	if err != nil {
+		return s, errors.Wrapf(err, "parsing state from %s", op.SchemaFile)
+	}
+	return s, nil
+}
+
+// checkoutRevision extracts the tree of the git revision into a temporary
+// directory keeping its layout, so that overlays, directories and references
+// between the schema files are read as in the working tree. It returns the
+// path of the schema files in it and the function to remove it.
+func checkoutRevision(rev, filePath string) (string, func(), error) {
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel", "--show-prefix").Output()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "searching git repository")
+	}
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	top, prefix := lines[0], ""
+	if len(lines) > 1 {
+		prefix = lines[1]
+	}
+
+	dir, err := ioutil.TempDir("", "migo-diff-")
+	if err != nil {
+		return "", nil, err
+	}
+	remove := func() { os.RemoveAll(dir) }
+
+	cmd := exec.Command("git", "archive", "--format=tar", rev)
+	cmd.Dir = top
+	r, err := cmd.StdoutPipe()
+	if err != nil {
+		remove()
+		return "", nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		remove()
+		return "", nil, errors.Wrapf(err, "archiving %s", rev)
+	}
+	if err := extractTar(r, dir); err != nil {
+		cmd.Wait()
+		remove()
+		return "", nil, errors.Wrapf(err, "extracting %s", rev)
+	}
+	if err := cmd.Wait(); err != nil {
+		remove()
+		return "", nil, errors.Wrapf(err, "archiving %s", rev)
+	}
+
+	files := []string{}
+	for _, f := range strings.Split(filePath, ",") {
+		rel := filepath.Join(filepath.FromSlash(prefix), f)
+		if filepath.IsAbs(f) {
+			if rel, err = filepath.Rel(top, f); err != nil {
+				remove()
+				return "", nil, err
+			}
+		}
+		files = append(files, filepath.Join(dir, rel))
+	}
+	return strings.Join(files, ","), remove, nil
+}
+
+// extractTar writes the directories and regular files of the tar archive under dir.
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		p := filepath.Join(dir, filepath.FromSlash(h.Name))
+		switch h.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(p, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+				return err
+			}
+			f, err := os.Create(p)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Diff prints the operations from the old schema to the new schema, where the
+// old schema is read from the git revision if it is given.
+func Diff(op DiffOption, w io.Writer) error {
+	oldFile := op.OldFile
+	if op.Revision != "" {
+		f, remove, err := checkoutRevision(op.Revision, op.OldFile)
+		if err != nil {
+			return err
+		}
+		defer remove()
+		oldFile = f
+	}
+
+	old, err := readSchemaState(schemaFileOption(oldFile, op.Environment, op.Schema))
+	if err != nil {
+		return err
+	}
+	new, err := readSchemaState(schemaFileOption(op.NewFile, op.Environment, op.Schema))
+	if err != nil {
+		return err
+	}
+
+	ops, err := NewOperations(old, new)
+	if err != nil {
+		return errors.Wrap(err, "creating requests")
+	}
+	return ops.Write(w, op.Format)
+}
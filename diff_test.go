@@ -0,0 +1,99 @@
+package migo_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/meta-closure/migo"
+)
+
+func TestDiff(t *testing.T) {
+	type Case struct {
+		input          migo.DiffOption
+		expectedOutput string
+		isSuccess      bool
+		spec           string
+	}
+
+	cases := []Case{
+		{
+			input: migo.DiffOption{
+				OldFile: "./test/diff_test_old.yml",
+				NewFile: "./test/diff_test_new.yml",
+				Format:  migo.FormatSQL,
+			},
+			expectedOutput: "ALTER TABLE user ADD COLUMN name varchar(255);\n",
+			isSuccess:      true,
+			spec:           "add column in sql",
+		},
+		{
+			input: migo.DiffOption{
+				OldFile: "./test/diff_test_old.yml",
+				NewFile: "./test/diff_test_new.yml",
+				Format:  migo.FormatText,
+			},
+			expectedOutput: "ADD COLUMN [name] IN [user]\n",
+			isSuccess:      true,
+			spec:           "add column in text",
+		},
+		{
+			input: migo.DiffOption{
+				OldFile: "./test/diff_test_new.yml",
+				NewFile: "./test/diff_test_new.yml",
+				Format:  migo.FormatJSON,
+			},
+			expectedOutput: "{\n  \"operations\": [],\n  \"warnings\": []\n}\n",
+			isSuccess:      true,
+			spec:           "no change in json",
+		},
+		{
+			input: migo.DiffOption{
+				OldFile:  "./test/diff_test_old.yml",
+				NewFile:  "./test/diff_test_new.yml",
+				Revision: "HEAD",
+				Format:   migo.FormatSQL,
+			},
+			expectedOutput: "ALTER TABLE user ADD COLUMN name varchar(255);\n",
+			isSuccess:      true,
+			spec:           "old schema at git revision",
+		},
+		{
+			input: migo.DiffOption{
+				OldFile:  "./test/diff_test_old.yml",
+				NewFile:  "./test/diff_test_new.yml",
+				Revision: "no-such-revision",
+			},
+			isSuccess: false,
+			spec:      "unknown git revision",
+		},
+		{
+			input: migo.DiffOption{
+				OldFile: "./test/diff_test_old.yml",
+				NewFile: "./test/parse_test_fail_by_pk.yml",
+			},
+			isSuccess: false,
+			spec:      "invalid new schema",
+		},
+	}
+
+	for _, c := range cases {
+		b := &bytes.Buffer{}
+		err := migo.Diff(c.input, b)
+		if !c.isSuccess && err == nil {
+			t.Errorf("in %s, error is expected but null", c.spec)
+			continue
+		}
+		if c.isSuccess && err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if b.String() != c.expectedOutput {
+			t.Errorf("in %s, expected output is %q, but actual %q", c.spec, c.expectedOutput, b.String())
+		}
+	}
+}
@@ -40,6 +40,42 @@ func (ops *Operations) UpdateTable(currentTable, newTable Table) error {
 		idx = append(idx, k)
 	}
 
+	for _, c := range newTable.Column {
+		if err := c.validate(); err != nil {
+			return err
+		}
+	}
+
+	chk := []Check{}
+	for _, c := range newTable.Check {
+		_, err := Table{Check: chk}.findCheckWithName(c.Name)
+		if err == nil {
+			return fmt.Errorf("check %s is not unique", c.Name)
+		}
+		chk = append(chk, c)
+	}
+
+	isColumnChanged, err := newTable.hasColumnChangesFrom(currentTable)
+	if err != nil {
+		return err
+	}
+	recreateTrigger := isColumnChanged || currentTable.Name != newTable.Name
+
+	for _, tr := range currentTable.Trigger {
+		new, err := newTable.findTriggerWithName(tr.Name)
+		if err != nil || recreateTrigger {
+			ops.Operation = append(ops.Operation, NewDropTrigger(currentTable, tr))
+			continue
+		}
+		isUpdated, err := new.isUpdatedFrom(tr)
+		if err != nil {
+			return err
+		}
+		if isUpdated {
+			ops.Operation = append(ops.Operation, NewDropTrigger(currentTable, tr))
+		}
+	}
+
 	if currentTable.Name != newTable.Name {
 		ops.Operation = append(ops.Operation, NewRenameTable(currentTable, newTable))
 	}
@@ -50,21 +86,44 @@ func (ops *Operations) UpdateTable(currentTable, newTable Table) error {
 		}
 	}
 
+	for _, c := range currentTable.Check {
+		old, err := newTable.findCheckWithName(c.Name)
+		if err != nil {
+			ops.Operation = append(ops.Operation, NewDropCheck(newTable, c))
+			continue
+		}
+		isUpdated, err := old.isUpdatedFrom(c)
+		if err != nil {
+			return err
+		}
+		if isUpdated {
+			ops.Operation = append(ops.Operation, NewDropCheck(newTable, c))
+		}
+	}
+
 	for _, k := range currentTable.PrimaryKey {
 		if !newTable.hasPrimaryKey(k) {
 			ops.Operation = append(ops.Operation, NewDropPrimaryKey(newTable, k))
 		}
 	}
 
+	dropped := []Column{}
 	for _, c := range currentTable.Column {
-		if !newTable.hasColumn(c) {
+		if _, err := newTable.findNewColumn(c); err != nil {
 			ops.Operation = append(ops.Operation, NewDropColumn(newTable, c))
+			dropped = append(dropped, c)
 		}
 	}
 
 	for _, c := range newTable.Column {
 		if !currentTable.hasColumn(c) {
 			ops.Operation = append(ops.Operation, NewAddColumn(newTable, c))
+			for _, d := range dropped {
+				if d.looksLike(c) {
+					ops.warn("COLUMN [%s] IN [%s] IS DROPPED AND [%s] IS ADDED, declare `renamed_from: %s` if it is renamed",
+						d.Name, newTable.Name, c.Name, d.Id)
+				}
+			}
 		}
 	}
 
@@ -109,13 +168,57 @@ func (ops *Operations) UpdateTable(currentTable, newTable Table) error {
 	}
 
 	for _, c := range newTable.Column {
-		old, err := currentTable.findColumnWithID(c.Id)
+		old, err := currentTable.findColumn(c)
 		if err != nil {
 			continue
 		}
+		isUpdated, err := c.isUpdatedFrom(old)
+		if err != nil {
+			return err
+		}
+		if !isUpdated {
+			continue
+		}
+		if !c.canChangeFrom(old) {
+			ops.Operation = append(ops.Operation, NewDropColumn(newTable, old))
+			ops.Operation = append(ops.Operation, NewAddColumn(newTable, c))
+			continue
+		}
 		ops.Operation = append(ops.Operation, NewUpdateColumn(newTable, old, c))
 	}
 
+	ops.UpdatePartition(newTable, currentTable.Partition, newTable.Partition)
+
+	for _, c := range newTable.Check {
+		old, err := currentTable.findCheckWithName(c.Name)
+		if err != nil {
+			ops.Operation = append(ops.Operation, NewAddCheck(newTable, c))
+			continue
+		}
+		isUpdated, err := c.isUpdatedFrom(old)
+		if err != nil {
+			return err
+		}
+		if isUpdated {
+			ops.Operation = append(ops.Operation, NewAddCheck(newTable, c))
+		}
+	}
+
+	for _, tr := range newTable.Trigger {
+		old, err := currentTable.findTriggerWithName(tr.Name)
+		if err != nil || recreateTrigger {
+			ops.Operation = append(ops.Operation, NewCreateTrigger(newTable, tr))
+			continue
+		}
+		isUpdated, err := tr.isUpdatedFrom(old)
+		if err != nil {
+			return err
+		}
+		if isUpdated {
+			ops.Operation = append(ops.Operation, NewCreateTrigger(newTable, tr))
+		}
+	}
+
 	return nil
 }
 
@@ -128,36 +231,178 @@ func (ops *Operations) DropTables(ts []Table) error {
 
 func (ops *Operations) CreateTables(ts []Table) error {
 	for _, t := range ts {
+		for _, c := range t.Column {
+			if err := c.validate(); err != nil {
+				return err
+			}
+		}
 		ops.Operation = append(ops.Operation, NewCreateTable(t))
+		for _, tr := range t.Trigger {
+			ops.Operation = append(ops.Operation, NewCreateTrigger(t, tr))
+		}
+	}
+	return nil
+}
+
+// DropViews drops the views which are removed, renamed or depend on a
+// changed table, and returns them to be created again by CreateViews.
+func (ops *Operations) DropViews(currentState, newState State) ([]View, error) {
+	changed, err := currentState.findChangedTableIDs(newState)
+	if err != nil {
+		return nil, err
+	}
+
+	dropped := []View{}
+	for _, v := range currentState.View {
+		new, err := newState.findViewWithID(v.Id)
+		if err != nil {
+			ops.Operation = append(ops.Operation, NewDropView(v))
+			continue
+		}
+
+		isDependent := false
+		for id := range changed {
+			if v.dependsOn(id) || new.dependsOn(id) {
+				isDependent = true
+			}
+		}
+		if isDependent || v.Name != new.Name {
+			ops.Operation = append(ops.Operation, NewDropView(v))
+			dropped = append(dropped, v)
+		}
+	}
+	return dropped, nil
+}
+
+func (ops *Operations) CreateViews(currentState, newState State, dropped []View) error {
+	isDropped := map[string]bool{}
+	for _, v := range dropped {
+		isDropped[v.Id] = true
+	}
+
+	for _, v := range newState.View {
+		old, err := currentState.findViewWithID(v.Id)
+		if err != nil || isDropped[v.Id] {
+			ops.Operation = append(ops.Operation, NewCreateView(v))
+			continue
+		}
+		isUpdated, err := v.isUpdatedFrom(old)
+		if err != nil {
+			return err
+		}
+		if isUpdated {
+			ops.Operation = append(ops.Operation, NewAlterView(old, v))
+		}
+	}
+	return nil
+}
+
+// DropRoutines drops the routines and events which are removed or changed.
+func (ops *Operations) DropRoutines(currentState, newState State) error {
+	for _, r := range currentState.Routine {
+		new, err := newState.findRoutineWithName(r.Name)
+		if err != nil {
+			ops.Operation = append(ops.Operation, NewDropRoutine(r))
+			continue
+		}
+		isUpdated, err := new.isUpdatedFrom(r)
+		if err != nil {
+			return err
+		}
+		if isUpdated {
+			ops.Operation = append(ops.Operation, NewDropRoutine(r))
+		}
+	}
+
+	for _, e := range currentState.Event {
+		new, err := newState.findEventWithName(e.Name)
+		if err != nil {
+			ops.Operation = append(ops.Operation, NewDropEvent(e))
+			continue
+		}
+		isUpdated, err := new.isUpdatedFrom(e)
+		if err != nil {
+			return err
+		}
+		if isUpdated {
+			ops.Operation = append(ops.Operation, NewDropEvent(e))
+		}
+	}
+	return nil
+}
+
+// CreateRoutines creates the routines and events which are added or changed.
+func (ops *Operations) CreateRoutines(currentState, newState State) error {
+	for _, r := range newState.Routine {
+		old, err := currentState.findRoutineWithName(r.Name)
+		if err != nil {
+			ops.Operation = append(ops.Operation, NewCreateRoutine(r))
+			continue
+		}
+		isUpdated, err := r.isUpdatedFrom(old)
+		if err != nil {
+			return err
+		}
+		if isUpdated {
+			ops.Operation = append(ops.Operation, NewCreateRoutine(r))
+		}
+	}
+
+	for _, e := range newState.Event {
+		old, err := currentState.findEventWithName(e.Name)
+		if err != nil {
+			ops.Operation = append(ops.Operation, NewCreateEvent(e))
+			continue
+		}
+		isUpdated, err := e.isUpdatedFrom(old)
+		if err != nil {
+			return err
+		}
+		if isUpdated {
+			ops.Operation = append(ops.Operation, NewCreateEvent(e))
+		}
 	}
 	return nil
 }
 
 func NewOperations(currentState, newState State) (Operations, error) {
 	ops := Operations{}
-	for _, fk := range currentState.ForeignKey {
-		ops.Operation = append(ops.Operation, NewDropForeignKey(fk))
+	if err := ops.DropRoutines(currentState, newState); err != nil {
+		return ops, err
 	}
-
-	ts, err := currentState.findTablesNotIn(newState)
+	views, err := ops.DropViews(currentState, newState)
 	if err != nil {
 		return ops, err
 	}
-	if err := ops.DropTables(ts); err != nil {
+
+	for _, fk := range currentState.ForeignKey {
+		ops.Operation = append(ops.Operation, NewDropForeignKey(fk))
+	}
+
+	dropped := currentState.findDroppedTables(newState)
+	if err := ops.DropTables(dropped); err != nil {
 		return ops, err
 	}
 
-	ts, err = newState.findTablesNotIn(currentState)
+	ts, err := newState.findTablesNotIn(currentState)
 	if err != nil {
 		return ops, err
 	}
 	if err := ops.CreateTables(ts); err != nil {
 		return ops, err
 	}
+	for _, t := range ts {
+		for _, d := range dropped {
+			if d.looksLike(t) {
+				ops.warn("TABLE [%s] IS DROPPED AND [%s] IS ADDED, declare `renamed_from: %s` if it is renamed",
+					d.Name, t.Name, d.Id)
+			}
+		}
+	}
 
 	ts, err = newState.findTablesIn(currentState)
 	for _, t := range ts {
-		s, err := currentState.findTableWithID(t.Id)
+		s, err := currentState.findTable(t)
 		if err != nil {
 			continue
 		}
@@ -170,5 +415,13 @@ func NewOperations(currentState, newState State) (Operations, error) {
 		ops.Operation = append(ops.Operation, NewAddForeignKey(fk))
 	}
 
+	if err := ops.CreateViews(currentState, newState, views); err != nil {
+		return ops, err
+	}
+	if err := ops.CreateRoutines(currentState, newState); err != nil {
+		return ops, err
+	}
+	ops.InsertDataSteps(currentState, newState)
+
 	return ops, nil
 }
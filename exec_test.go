@@ -14,10 +14,11 @@ func TestNewOperations(t *testing.T) {
 	}
 
 	type Case struct {
-		input           Input
-		expectedQueries []string
-		isSuccess       bool
-		spec            string
+		input            Input
+		expectedQueries  []string
+		expectedWarnings []string
+		isSuccess        bool
+		spec             string
 	}
 
 	cases := []Case{
@@ -94,6 +95,329 @@ func TestNewOperations(t *testing.T) {
 			},
 			isSuccess: true,
 		},
+		{
+			spec: "recreate view around column change",
+			input: Input{
+				CurrentState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:   "#/definitions/user",
+							Name: "user",
+							Column: []migo.Column{
+								{
+									Id:   "name",
+									Name: "name",
+									Type: "text",
+								},
+							},
+						},
+					},
+					View: []migo.View{
+						{
+							Id:         "#/definitions/user_name",
+							Name:       "user_name",
+							Definition: "SELECT name FROM user",
+							DependsOn:  []string{"#/definitions/user"},
+						},
+						{
+							Id:         "#/definitions/old_view",
+							Name:       "old_view",
+							Definition: "SELECT 1",
+						},
+						{
+							Id:         "#/definitions/constant",
+							Name:       "constant",
+							Definition: "SELECT 1",
+						},
+					},
+				},
+				NewState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:   "#/definitions/user",
+							Name: "user",
+							Column: []migo.Column{
+								{
+									Id:   "name",
+									Name: "full_name",
+									Type: "text",
+								},
+							},
+						},
+					},
+					View: []migo.View{
+						{
+							Id:         "#/definitions/user_name",
+							Name:       "user_name",
+							Definition: "SELECT full_name FROM user",
+							DependsOn:  []string{"#/definitions/user"},
+						},
+						{
+							Id:         "#/definitions/constant",
+							Name:       "constant",
+							Definition: "SELECT 2",
+							Algorithm:  "merge",
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"DROP VIEW user_name",
+				"DROP VIEW old_view",
+				"ALTER TABLE user CHANGE COLUMN name full_name text",
+				"CREATE VIEW user_name AS SELECT full_name FROM user",
+				"ALTER ALGORITHM=MERGE VIEW constant AS SELECT 2",
+			},
+			isSuccess: true,
+		},
+		{
+			spec: "replace changed routine and event",
+			input: Input{
+				CurrentState: migo.State{
+					Routine: []migo.Routine{
+						{
+							Name:     "purge",
+							Type:     "procedure",
+							Body:     "DELETE FROM session",
+							Checksum: "before",
+						},
+						{
+							Name:     "unchanged",
+							Type:     "procedure",
+							Body:     "SELECT 1",
+							Checksum: "unchanged",
+						},
+					},
+					Event: []migo.Event{
+						{
+							Name:     "nightly_purge",
+							Schedule: "EVERY 1 DAY",
+							Body:     "CALL purge()",
+							Checksum: "before",
+						},
+					},
+				},
+				NewState: migo.State{
+					Routine: []migo.Routine{
+						{
+							Name:     "purge",
+							Type:     "procedure",
+							Body:     "DELETE FROM session WHERE expired_at < NOW()",
+							Checksum: "after",
+						},
+						{
+							Name:     "unchanged",
+							Type:     "procedure",
+							Body:     "SELECT 1",
+							Checksum: "unchanged",
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"DROP PROCEDURE purge",
+				"DROP EVENT nightly_purge",
+				"CREATE PROCEDURE purge() DELETE FROM session WHERE expired_at < NOW()",
+			},
+			isSuccess: true,
+		},
+		{
+			spec: "rename table and column with renamed_from",
+			input: Input{
+				CurrentState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:   "#/definitions/user",
+							Name: "user",
+							Column: []migo.Column{
+								{
+									Id:   "name",
+									Name: "name",
+									Type: "text",
+								},
+							},
+						},
+					},
+				},
+				NewState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:          "#/definitions/account",
+							RenamedFrom: "#/definitions/user",
+							Name:        "account",
+							Column: []migo.Column{
+								{
+									Id:          "full_name",
+									RenamedFrom: "name",
+									Name:        "full_name",
+									Type:        "text",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE user RENAME account",
+				"ALTER TABLE account CHANGE COLUMN name full_name text",
+			},
+			isSuccess: true,
+		},
+		{
+			spec: "renamed_from hint of current state does not match new column",
+			input: Input{
+				CurrentState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:   "#/definitions/user",
+							Name: "user",
+							Column: []migo.Column{
+								{
+									Id:          "b",
+									RenamedFrom: "a",
+									Name:        "b",
+									Type:        "text",
+								},
+							},
+						},
+					},
+				},
+				NewState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:   "#/definitions/user",
+							Name: "user",
+							Column: []migo.Column{
+								{
+									Id:   "a",
+									Name: "a",
+									Type: "text",
+								},
+								{
+									Id:          "b",
+									RenamedFrom: "a",
+									Name:        "b",
+									Type:        "text",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE user ADD COLUMN a text",
+			},
+			isSuccess: true,
+		},
+		{
+			spec: "no warning for drop and add of different columns",
+			input: Input{
+				CurrentState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:   "#/definitions/user",
+							Name: "user",
+							Column: []migo.Column{
+								{
+									Id:      "name",
+									Name:    "name",
+									Type:    "text",
+									NotNull: true,
+								},
+							},
+						},
+					},
+				},
+				NewState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:   "#/definitions/user",
+							Name: "user",
+							Column: []migo.Column{
+								{
+									Id:   "note",
+									Name: "note",
+									Type: "text",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE user DROP COLUMN name",
+				"ALTER TABLE user ADD COLUMN note text",
+			},
+			isSuccess: true,
+		},
+		{
+			spec: "warn drop and add which look like rename",
+			input: Input{
+				CurrentState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:   "#/definitions/user",
+							Name: "user",
+							Column: []migo.Column{
+								{
+									Id:   "name",
+									Name: "name",
+									Type: "text",
+								},
+							},
+						},
+						{
+							Id:   "#/definitions/log",
+							Name: "log",
+							Column: []migo.Column{
+								{
+									Id:   "id",
+									Name: "id",
+									Type: "int",
+								},
+							},
+						},
+					},
+				},
+				NewState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:   "#/definitions/user",
+							Name: "user",
+							Column: []migo.Column{
+								{
+									Id:   "full_name",
+									Name: "full_name",
+									Type: "TEXT",
+								},
+							},
+						},
+						{
+							Id:   "#/definitions/history",
+							Name: "history",
+							Column: []migo.Column{
+								{
+									Id:   "id",
+									Name: "id",
+									Type: "int(11)",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"DROP TABLE log",
+				"CREATE TABLE history (id int(11))ENGINE=innoDB",
+				"ALTER TABLE user DROP COLUMN name",
+				"ALTER TABLE user ADD COLUMN full_name TEXT",
+			},
+			expectedWarnings: []string{
+				"TABLE [log] IS DROPPED AND [history] IS ADDED, declare `renamed_from: #/definitions/log` if it is renamed",
+				"COLUMN [name] IN [user] IS DROPPED AND [full_name] IS ADDED, declare `renamed_from: name` if it is renamed",
+			},
+			isSuccess: true,
+		},
 		{
 			spec: "drop table with foreign key",
 			input: Input{
@@ -167,141 +491,595 @@ func TestNewOperations(t *testing.T) {
 			},
 			isSuccess: true,
 		},
-	}
-
-	for _, c := range cases {
-		op, err := migo.NewOperations(c.input.CurrentState, c.input.NewState)
-		if !c.isSuccess && err == nil {
-			t.Errorf("in %s, error is expected but null", c.spec)
-			continue
-		}
-		if c.isSuccess && err != nil {
-			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
-			continue
-		}
-
-		if err != nil {
-			continue
-		}
-
-		if len(op.Operation) != len(c.expectedQueries) {
-			t.Errorf("in %s, expected query length is %d, but actual %d", c.spec, len(c.expectedQueries), len(op.Operation))
-			continue
-		}
-		for i := range op.Operation {
-			if c.expectedQueries[i] != op.Operation[i].Query() {
-				t.Errorf("in %s, expected query is %s, but actual %s", c.spec, c.expectedQueries[i], op.Operation[i].Query())
-			}
-		}
-	}
-}
-
-func TestCreateTables(t *testing.T) {
-	type Input struct {
-		state  migo.State
-		tables []migo.Table
-	}
-	type Case struct {
-		input           Input
-		expectedQueries []string
-		isSuccess       bool
-		spec            string
-	}
-
-	cases := []Case{
 		{
-			spec: "create table",
+			spec: "run data steps around the column change",
+			input: Input{
+				CurrentState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:     "#/definitions/user",
+							Name:   "user",
+							Column: []migo.Column{{Id: "name", Name: "name", Type: "varchar(255)"}},
+						},
+					},
+				},
+				NewState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:   "#/definitions/user",
+							Name: "user",
+							Column: []migo.Column{
+								{Id: "name", Name: "name", Type: "varchar(255)"},
+								{Id: "full_name", Name: "full_name", Type: "varchar(255)"},
+							},
+							Data: []migo.DataStep{
+								{Name: "fill_full_name", Timing: "after", Column: "full_name", SQL: "UPDATE user SET full_name = name WHERE {{chunk}}", ChunkSize: 1000},
+								{Name: "log_user", Timing: "before", SQL: "INSERT INTO migration_log VALUES ('user')"},
+								{Name: "fill_name", Timing: "after", Column: "name", SQL: "UPDATE user SET name = ''"},
+							},
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"INSERT INTO migration_log VALUES ('user')",
+				"ALTER TABLE user ADD COLUMN full_name varchar(255)",
+				"UPDATE user SET full_name = name WHERE 1 = 1",
+			},
+			isSuccess: true,
+		},
+		{
+			spec: "add NOT NULL column as nullable until data step is run",
+			input: Input{
+				CurrentState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:     "#/definitions/user",
+							Name:   "user",
+							Column: []migo.Column{{Id: "name", Name: "name", Type: "varchar(255)"}},
+						},
+					},
+				},
+				NewState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:   "#/definitions/user",
+							Name: "user",
+							Column: []migo.Column{
+								{Id: "name", Name: "name", Type: "varchar(255)", NotNull: true},
+								{Id: "full_name", Name: "full_name", Type: "varchar(255)", NotNull: true},
+							},
+							Data: []migo.DataStep{
+								{Name: "fill_full_name", Timing: "after", Column: "full_name", SQL: "UPDATE user SET full_name = name"},
+								{Name: "fill_name", Timing: "after", Column: "name", SQL: "UPDATE user SET name = '' WHERE name IS NULL"},
+							},
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE user ADD COLUMN full_name varchar(255)",
+				"UPDATE user SET full_name = name",
+				"ALTER TABLE user CHANGE COLUMN full_name full_name varchar(255) NOT NULL",
+				"UPDATE user SET name = '' WHERE name IS NULL",
+				"ALTER TABLE user CHANGE COLUMN name name varchar(255) NOT NULL",
+			},
+			isSuccess: true,
+		},
+		{
+			spec: "run data step before the column is dropped",
+			input: Input{
+				CurrentState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:   "#/definitions/user",
+							Name: "user",
+							Column: []migo.Column{
+								{Id: "name", Name: "name", Type: "varchar(255)"},
+								{Id: "nickname", Name: "nickname", Type: "varchar(255)"},
+							},
+						},
+					},
+				},
+				NewState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:     "#/definitions/user",
+							Name:   "user",
+							Column: []migo.Column{{Id: "name", Name: "name", Type: "varchar(255)"}},
+							Data: []migo.DataStep{
+								{Name: "keep_nickname", Timing: "before", Column: "nickname", SQL: "UPDATE user SET name = nickname WHERE name IS NULL"},
+							},
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"UPDATE user SET name = nickname WHERE name IS NULL",
+				"ALTER TABLE user DROP COLUMN nickname",
+			},
+			isSuccess: true,
+		},
+		{
+			spec: "skip data step applied in current state",
+			input: Input{
+				CurrentState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:     "#/definitions/user",
+							Name:   "user",
+							Column: []migo.Column{{Id: "name", Name: "name", Type: "varchar(255)"}},
+						},
+					},
+					AppliedDataSteps: []string{"#/definitions/user/table/data/fill_full_name"},
+				},
+				NewState: migo.State{
+					Tables: []migo.Table{
+						{
+							Id:   "#/definitions/user",
+							Name: "user",
+							Column: []migo.Column{
+								{Id: "name", Name: "name", Type: "varchar(255)"},
+								{Id: "full_name", Name: "full_name", Type: "varchar(255)"},
+							},
+							Data: []migo.DataStep{
+								{Name: "fill_full_name", Timing: "after", Column: "full_name", SQL: "UPDATE user SET full_name = name"},
+							},
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE user ADD COLUMN full_name varchar(255)",
+			},
+			isSuccess: true,
+		},
+	}
+
+	for _, c := range cases {
+		op, err := migo.NewOperations(c.input.CurrentState, c.input.NewState)
+		if !c.isSuccess && err == nil {
+			t.Errorf("in %s, error is expected but null", c.spec)
+			continue
+		}
+		if c.isSuccess && err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if len(op.Operation) != len(c.expectedQueries) {
+			t.Errorf("in %s, expected query length is %d, but actual %d", c.spec, len(c.expectedQueries), len(op.Operation))
+			continue
+		}
+		for i := range op.Operation {
+			if c.expectedQueries[i] != op.Operation[i].Query() {
+				t.Errorf("in %s, expected query is %s, but actual %s", c.spec, c.expectedQueries[i], op.Operation[i].Query())
+			}
+		}
+
+		if len(op.Warning) != len(c.expectedWarnings) {
+			t.Errorf("in %s, expected warnings are %v, but actual %v", c.spec, c.expectedWarnings, op.Warning)
+			continue
+		}
+		for i := range op.Warning {
+			if c.expectedWarnings[i] != op.Warning[i] {
+				t.Errorf("in %s, expected warning is %s, but actual %s", c.spec, c.expectedWarnings[i], op.Warning[i])
+			}
+		}
+	}
+}
+
+func TestCreateTables(t *testing.T) {
+	type Input struct {
+		state  migo.State
+		tables []migo.Table
+	}
+	type Case struct {
+		input           Input
+		expectedQueries []string
+		isSuccess       bool
+		spec            string
+	}
+
+	cases := []Case{
+		{
+			spec: "create table",
+			input: Input{
+				tables: []migo.Table{
+					{
+						Id:   "table1_id",
+						Name: "table1",
+					},
+					{
+						Id:   "table2_id",
+						Name: "table2",
+					},
+				},
+			},
+			expectedQueries: []string{
+				"CREATE TABLE table1 ()ENGINE=innoDB",
+				"CREATE TABLE table2 ()ENGINE=innoDB",
+			},
+			isSuccess: true,
+		},
+		{
+			spec: "create table with default",
+			input: Input{
+				tables: []migo.Table{
+					{
+						Id:   "table1_id",
+						Name: "table1",
+						Column: []migo.Column{
+							{
+								Id:      "column1",
+								Name:    "column1",
+								Type:    "char(36)",
+								Default: migo.Default{Type: migo.DefaultExpression, Value: "UUID()"},
+							},
+							{
+								Id:      "column2",
+								Name:    "column2",
+								Type:    "varchar(16)",
+								Default: migo.Default{Type: migo.DefaultString, Value: "it's"},
+							},
+							{
+								Id:      "column3",
+								Name:    "column3",
+								Type:    "tinyint(1)",
+								Default: migo.Default{Type: migo.DefaultBoolean, Value: "true"},
+							},
+							{
+								Id:      "column4",
+								Name:    "column4",
+								Type:    "datetime",
+								Default: migo.Default{Type: migo.DefaultString, Value: "2000-01-01 00:00:00"},
+							},
+							{
+								Id:      "column5",
+								Name:    "column5",
+								Type:    "text",
+								Default: migo.Default{Type: migo.DefaultNull},
+							},
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"CREATE TABLE table1 (column1 char(36) DEFAULT (UUID()),column2 varchar(16) DEFAULT 'it''s',column3 tinyint(1) DEFAULT TRUE,column4 datetime DEFAULT '2000-01-01 00:00:00',column5 text DEFAULT NULL)ENGINE=innoDB",
+			},
+			isSuccess: true,
+		},
+		{
+			spec: "create table with implicit datetime default",
+			input: Input{
+				tables: []migo.Table{
+					{
+						Id:   "table1_id",
+						Name: "table1",
+						Column: []migo.Column{
+							{
+								Id:      "created_at",
+								Name:    "created_at",
+								Type:    "datetime",
+								NotNull: true,
+							},
+							{
+								Id:         "updated_at",
+								Name:       "updated_at",
+								Type:       "timestamp(3)",
+								AutoUpdate: true,
+							},
+							{
+								Id:   "deleted_at",
+								Name: "deleted_at",
+								Type: "datetime",
+							},
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"CREATE TABLE table1 (created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,updated_at timestamp(3) ON UPDATE CURRENT_TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP(3),deleted_at datetime)ENGINE=innoDB",
+			},
+			isSuccess: true,
+		},
+		{
+			spec: "create indiced table",
+			input: Input{
+				tables: []migo.Table{
+					{
+						Id:   "table1_id",
+						Name: "table1",
+						Column: []migo.Column{
+							{
+								Id:   "indiced_column",
+								Name: "column1",
+								Type: "type1",
+							},
+						},
+						Index: []migo.Key{
+							{
+								Name: "test_index",
+								Target: []migo.Column{
+									{
+										Id:   "indiced_column",
+										Name: "column1",
+										Type: "type1",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"CREATE TABLE table1 (column1 type1,INDEX test_index (column1))ENGINE=innoDB",
+			},
+			isSuccess: true,
+		},
+	}
+	for _, c := range cases {
+		op := migo.Operations{}
+		err := op.CreateTables(c.input.tables)
+
+		if !c.isSuccess && err == nil {
+			t.Errorf("in %s, error is expected but null", c.spec)
+			continue
+		}
+		if c.isSuccess && err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+			continue
+		}
+
+		if len(op.Operation) != len(c.expectedQueries) {
+			t.Errorf("in %s, expected query length is %d, but actual %d", c.spec, len(c.expectedQueries), len(op.Operation))
+			continue
+		}
+		for i := range op.Operation {
+			if c.expectedQueries[i] != op.Operation[i].Query() {
+				t.Errorf("in %s, expected query is %s, but actual %s", c.spec, c.expectedQueries[i], op.Operation[i].Query())
+			}
+		}
+	}
+}
+
+func TestUpdateTable(t *testing.T) {
+	type Input struct {
+		CurrentTable migo.Table
+		NewTable     migo.Table
+	}
+	type Case struct {
+		input           Input
+		expectedQueries []string
+		isSuccess       bool
+		spec            string
+	}
+
+	cases := []Case{
+		{
+			input: Input{
+				CurrentTable: migo.Table{
+					Id:   "#/definitions/table",
+					Name: "before",
+					Column: []migo.Column{
+						{
+							Id:   "column",
+							Name: "column",
+						},
+					},
+				},
+				NewTable: migo.Table{
+					Id:   "#/definitions/table",
+					Name: "after",
+					Column: []migo.Column{
+						{
+							Id:   "column",
+							Name: "column",
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE before RENAME after",
+			},
+			isSuccess: true,
+			spec:      "rename table",
+		},
+		{
+			input: Input{
+				CurrentTable: migo.Table{
+					Id:   "#/definitions/table",
+					Name: "table",
+					Column: []migo.Column{
+						{
+							Id:   "column",
+							Name: "before_column",
+						},
+					},
+				},
+				NewTable: migo.Table{
+					Id:   "#/definitions/table",
+					Name: "table",
+					Column: []migo.Column{
+						{
+							Id:            "column",
+							Name:          "after_column",
+							Type:          "type",
+							AutoIncrement: true,
+							Unique:        true,
+							NotNull:       true,
+							Default:       migo.Default{Type: migo.DefaultString, Value: "default"},
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE table CHANGE COLUMN before_column after_column type AUTO_INCREMENT NOT NULL UNIQUE DEFAULT 'default'",
+			},
+			isSuccess: true,
+			spec:      "update column field",
+		},
+		{
+			input: Input{
+				CurrentTable: migo.Table{
+					Id:   "#/definitions/table",
+					Name: "table",
+					Column: []migo.Column{
+						{
+							Id:   "column",
+							Name: "column",
+							Type: "varchar(255)",
+						},
+					},
+				},
+				NewTable: migo.Table{
+					Id:   "#/definitions/table",
+					Name: "table",
+					Column: []migo.Column{
+						{
+							Id:        "column",
+							Name:      "column",
+							Type:      "varchar(255)",
+							Charset:   "utf8mb4",
+							Collation: "utf8mb4_bin",
+							Comment:   "user's token",
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE table CHANGE COLUMN column column varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin COMMENT 'user''s token'",
+			},
+			isSuccess: true,
+			spec:      "update column charset, collation and comment",
+		},
+		{
+			input: Input{
+				CurrentTable: migo.Table{
+					Id:   "#/definitions/table",
+					Name: "table",
+					Column: []migo.Column{
+						{
+							Id:   "column1",
+							Name: "column1",
+							Type: "INT",
+						},
+						{
+							Id:   "column2",
+							Name: "column2",
+							Type: "bool",
+						},
+					},
+				},
+				NewTable: migo.Table{
+					Id:   "#/definitions/table",
+					Name: "table",
+					Column: []migo.Column{
+						{
+							Id:   "column1",
+							Name: "column1",
+							Type: "int(11)",
+						},
+						{
+							Id:   "column2",
+							Name: "column2",
+							Type: "tinyint(1)",
+						},
+					},
+				},
+			},
+			expectedQueries: []string{},
+			isSuccess:       true,
+			spec:            "equivalent column type spelling",
+		},
+		{
 			input: Input{
-				tables: []migo.Table{
-					{
-						Id:   "table1_id",
-						Name: "table1",
+				CurrentTable: migo.Table{
+					Id:   "#/definitions/table",
+					Name: "table",
+					Column: []migo.Column{
+						{
+							Id:   "column",
+							Name: "updated_at",
+							Type: "timestamp",
+						},
 					},
-					{
-						Id:   "table2_id",
-						Name: "table2",
+				},
+				NewTable: migo.Table{
+					Id:   "#/definitions/table",
+					Name: "table",
+					Column: []migo.Column{
+						{
+							Id:         "column",
+							Name:       "updated_at",
+							Type:       "timestamp(3)",
+							AutoUpdate: true,
+						},
 					},
 				},
 			},
 			expectedQueries: []string{
-				"CREATE TABLE table1 ()ENGINE=innoDB",
-				"CREATE TABLE table2 ()ENGINE=innoDB",
+				"ALTER TABLE table CHANGE COLUMN updated_at updated_at timestamp(3) ON UPDATE CURRENT_TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP(3)",
 			},
 			isSuccess: true,
+			spec:      "update timestamp column",
 		},
 		{
-			spec: "create indiced table",
 			input: Input{
-				tables: []migo.Table{
-					{
-						Id:   "table1_id",
-						Name: "table1",
-						Column: []migo.Column{
-							{
-								Id:   "indiced_column",
-								Name: "column1",
-								Type: "type1",
-							},
+				CurrentTable: migo.Table{
+					Id:   "#/definitions/table",
+					Name: "table",
+					Column: []migo.Column{
+						{
+							Id:   "price",
+							Name: "price",
+							Type: "int",
 						},
-						Index: []migo.Key{
-							{
-								Name: "test_index",
-								Target: []migo.Column{
-									{
-										Id:   "indiced_column",
-										Name: "column1",
-										Type: "type1",
-									},
-								},
-							},
+						{
+							Id:         "total",
+							Name:       "total",
+							Type:       "int",
+							Expression: "price * 2",
+						},
+					},
+				},
+				NewTable: migo.Table{
+					Id:   "#/definitions/table",
+					Name: "table",
+					Column: []migo.Column{
+						{
+							Id:   "price",
+							Name: "price",
+							Type: "int",
+						},
+						{
+							Id:         "search_key",
+							Name:       "search_key",
+							Type:       "varchar(16)",
+							Expression: "LOWER(price)",
+						},
+						{
+							Id:         "total",
+							Name:       "total",
+							Type:       "int",
+							Expression: "price * 3",
+							Storage:    "stored",
 						},
 					},
 				},
 			},
 			expectedQueries: []string{
-				"CREATE TABLE table1 (column1 type1,INDEX test_index (column1))ENGINE=innoDB",
+				"ALTER TABLE table ADD COLUMN search_key varchar(16) GENERATED ALWAYS AS (LOWER(price)) VIRTUAL",
+				"ALTER TABLE table DROP COLUMN total",
+				"ALTER TABLE table ADD COLUMN total int GENERATED ALWAYS AS (price * 3) STORED",
 			},
 			isSuccess: true,
+			spec:      "generated column",
 		},
-	}
-	for _, c := range cases {
-		op := migo.Operations{}
-		err := op.CreateTables(c.input.tables)
-
-		if !c.isSuccess && err == nil {
-			t.Errorf("in %s, error is expected but null", c.spec)
-			continue
-		}
-		if c.isSuccess && err != nil {
-			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
-			continue
-		}
-
-		if len(op.Operation) != len(c.expectedQueries) {
-			t.Errorf("in %s, expected query length is %d, but actual %d", c.spec, len(c.expectedQueries), len(op.Operation))
-			continue
-		}
-		for i := range op.Operation {
-			if c.expectedQueries[i] != op.Operation[i].Query() {
-				t.Errorf("in %s, expected query is %s, but actual %s", c.spec, c.expectedQueries[i], op.Operation[i].Query())
-			}
-		}
-	}
-}
-
-func TestUpdateTable(t *testing.T) {
-	type Input struct {
-		CurrentTable migo.Table
-		NewTable     migo.Table
-	}
-	type Case struct {
-		input           Input
-		expectedQueries []string
-		isSuccess       bool
-		spec            string
-	}
-
-	cases := []Case{
 		{
 			input: Input{
 				CurrentTable: migo.Table{
@@ -313,6 +1091,14 @@ func TestUpdateTable(t *testing.T) {
 							Name: "column",
 						},
 					},
+					Trigger: []migo.Trigger{
+						{
+							Name:   "audit",
+							Timing: "before",
+							Event:  "update",
+							Body:   "SET NEW.column = 1",
+						},
+					},
 				},
 				NewTable: migo.Table{
 					Id:   "#/definitions/table",
@@ -323,14 +1109,23 @@ func TestUpdateTable(t *testing.T) {
 							Name: "column",
 						},
 					},
+					Trigger: []migo.Trigger{
+						{
+							Name:   "audit",
+							Timing: "before",
+							Event:  "update",
+							Body:   "SET NEW.column = 1",
+						},
+					},
 				},
 			},
 			expectedQueries: []string{
+				"DROP TRIGGER audit",
 				"ALTER TABLE before RENAME after",
-				"ALTER TABLE after CHANGE COLUMN column column ",
+				"CREATE TRIGGER audit BEFORE UPDATE ON after FOR EACH ROW SET NEW.column = 1",
 			},
 			isSuccess: true,
-			spec:      "rename table",
+			spec:      "rename table with trigger",
 		},
 		{
 			input: Input{
@@ -340,7 +1135,21 @@ func TestUpdateTable(t *testing.T) {
 					Column: []migo.Column{
 						{
 							Id:   "column",
-							Name: "before_column",
+							Name: "column",
+						},
+					},
+					Trigger: []migo.Trigger{
+						{
+							Name:   "audit",
+							Timing: "before",
+							Event:  "update",
+							Body:   "SET NEW.column = 1",
+						},
+						{
+							Name:   "removed",
+							Timing: "after",
+							Event:  "delete",
+							Body:   "SET @count = @count + 1",
 						},
 					},
 				},
@@ -349,22 +1158,99 @@ func TestUpdateTable(t *testing.T) {
 					Name: "table",
 					Column: []migo.Column{
 						{
-							Id:            "column",
-							Name:          "after_column",
-							Type:          "type",
-							AutoIncrement: true,
-							Unique:        true,
-							NotNull:       true,
-							Default:       "default",
+							Id:   "column",
+							Name: "column",
+						},
+					},
+					Trigger: []migo.Trigger{
+						{
+							Name:   "audit",
+							Timing: "before",
+							Event:  "insert",
+							Body:   "SET NEW.column = 2",
 						},
 					},
 				},
 			},
 			expectedQueries: []string{
-				"ALTER TABLE table CHANGE COLUMN before_column after_column type AUTO_INCREMENT NOT NULL UNIQUE DEFAULT 'default'",
+				"DROP TRIGGER audit",
+				"DROP TRIGGER removed",
+				"CREATE TRIGGER audit BEFORE INSERT ON table FOR EACH ROW SET NEW.column = 2",
 			},
 			isSuccess: true,
-			spec:      "update column field",
+			spec:      "update trigger",
+		},
+		{
+			input: Input{
+				CurrentTable: migo.Table{
+					Id:   "#/definitions/table",
+					Name: "table",
+				},
+				NewTable: migo.Table{
+					Id:   "#/definitions/table",
+					Name: "table",
+					Column: []migo.Column{
+						{
+							Id:         "total",
+							Name:       "total",
+							Type:       "int",
+							Expression: "price * 2",
+							Default:    migo.Default{Type: migo.DefaultNumber, Value: "0"},
+						},
+					},
+				},
+			},
+			isSuccess: false,
+			spec:      "generated column with default",
+		},
+		{
+			input: Input{
+				CurrentTable: migo.Table{
+					Id:   "#/definitions/table",
+					Name: "table",
+					Column: []migo.Column{
+						{
+							Id:   "column",
+							Name: "age",
+							Type: "int",
+						},
+					},
+					Check: []migo.Check{
+						{
+							Name:       "table_age_range_chk",
+							Expression: "age >= 0",
+						},
+						{
+							Name:       "table_age_enum_chk",
+							Expression: "age IN (1,2)",
+						},
+					},
+				},
+				NewTable: migo.Table{
+					Id:   "#/definitions/table",
+					Name: "table",
+					Column: []migo.Column{
+						{
+							Id:   "column",
+							Name: "age",
+							Type: "int",
+						},
+					},
+					Check: []migo.Check{
+						{
+							Name:       "table_age_range_chk",
+							Expression: "age BETWEEN 0 AND 150",
+						},
+					},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE table DROP CHECK table_age_range_chk",
+				"ALTER TABLE table DROP CHECK table_age_enum_chk",
+				"ALTER TABLE table ADD CONSTRAINT table_age_range_chk CHECK (age BETWEEN 0 AND 150)",
+			},
+			isSuccess: true,
+			spec:      "update check constraint",
 		},
 		{
 			input: Input{
@@ -395,7 +1281,6 @@ func TestUpdateTable(t *testing.T) {
 			},
 			expectedQueries: []string{
 				"ALTER TABLE table DROP COLUMN before_column",
-				"ALTER TABLE table CHANGE COLUMN column column ",
 			},
 			isSuccess: true,
 			spec:      "delete column",
@@ -471,8 +1356,6 @@ func TestUpdateTable(t *testing.T) {
 			},
 			expectedQueries: []string{
 				"ALTER TABLE table ADD PRIMARY KEY key (primary_key_column1,primary_key_column2)",
-				"ALTER TABLE table CHANGE COLUMN primary_key_column1 primary_key_column1 ",
-				"ALTER TABLE table CHANGE COLUMN primary_key_column2 primary_key_column2 ",
 			},
 			isSuccess: true,
 			spec:      "add primary key",
@@ -525,8 +1408,6 @@ func TestUpdateTable(t *testing.T) {
 			},
 			expectedQueries: []string{
 				"ALTER TABLE table DROP PRIMARY KEY",
-				"ALTER TABLE table CHANGE COLUMN primary_key_column1 primary_key_column1 ",
-				"ALTER TABLE table CHANGE COLUMN primary_key_column2 primary_key_column2 ",
 			},
 			isSuccess: true,
 			spec:      "drop primary key",
@@ -579,8 +1460,6 @@ func TestUpdateTable(t *testing.T) {
 			},
 			expectedQueries: []string{
 				"ALTER TABLE table ADD INDEX key (index_column1,index_column2)",
-				"ALTER TABLE table CHANGE COLUMN index_column1 index_column1 ",
-				"ALTER TABLE table CHANGE COLUMN index_column2 index_column2 ",
 			},
 			isSuccess: true,
 			spec:      "add index",
@@ -633,8 +1512,6 @@ func TestUpdateTable(t *testing.T) {
 			},
 			expectedQueries: []string{
 				"ALTER TABLE table DROP INDEX key",
-				"ALTER TABLE table CHANGE COLUMN index_column1 index_column1 ",
-				"ALTER TABLE table CHANGE COLUMN index_column2 index_column2 ",
 			},
 			isSuccess: true,
 			spec:      "drop index",
@@ -723,7 +1600,6 @@ func TestUpdateTable(t *testing.T) {
 			expectedQueries: []string{
 				"ALTER TABLE table DROP PRIMARY KEY",
 				"ALTER TABLE table DROP COLUMN column1",
-				"ALTER TABLE table CHANGE COLUMN column2 column2 int",
 			},
 		},
 		{
@@ -810,7 +1686,6 @@ func TestUpdateTable(t *testing.T) {
 			expectedQueries: []string{
 				"ALTER TABLE table DROP INDEX column_index",
 				"ALTER TABLE table DROP COLUMN column1",
-				"ALTER TABLE table CHANGE COLUMN column2 column2 int",
 			},
 		},
 		{
@@ -878,7 +1753,6 @@ func TestUpdateTable(t *testing.T) {
 				"ALTER TABLE table DROP COLUMN column2",
 				"ALTER TABLE table DROP INDEX column_index",
 				"ALTER TABLE table ADD INDEX column_index (column1)",
-				"ALTER TABLE table CHANGE COLUMN column1 column1 int",
 			},
 		},
 		{
@@ -946,7 +1820,6 @@ func TestUpdateTable(t *testing.T) {
 				"ALTER TABLE table DROP COLUMN column2",
 				"ALTER TABLE table DROP PRIMARY KEY",
 				"ALTER TABLE table ADD PRIMARY KEY column_primary_key (column1)",
-				"ALTER TABLE table CHANGE COLUMN column1 column1 int",
 			},
 		},
 		{
@@ -1077,6 +1950,112 @@ func TestUpdateTable(t *testing.T) {
 			isSuccess: false,
 			spec:      "index name is not unique",
 		},
+		{
+			input: Input{
+				CurrentTable: migo.Table{
+					Id:        "#/definitions/event",
+					Name:      "event",
+					Column:    []migo.Column{{Id: "created_at", Name: "created_at", Type: "date"}},
+					Partition: migo.Partition{},
+				},
+				NewTable: migo.Table{
+					Id:        "#/definitions/event",
+					Name:      "event",
+					Column:    []migo.Column{{Id: "created_at", Name: "created_at", Type: "date"}},
+					Partition: migo.Partition{Type: "range", Columns: []string{"created_at"}, Partitions: migo.PartitionDefinitions{{Name: "p202601", Values: "'2026-02-01'"}}},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE event PARTITION BY RANGE COLUMNS(created_at) (PARTITION p202601 VALUES LESS THAN ('2026-02-01'))",
+			},
+			isSuccess: true,
+			spec:      "partition table",
+		},
+		{
+			input: Input{
+				CurrentTable: migo.Table{
+					Id:        "#/definitions/event",
+					Name:      "event",
+					Column:    []migo.Column{{Id: "created_at", Name: "created_at", Type: "date"}},
+					Partition: migo.Partition{Type: "range", Columns: []string{"created_at"}, Partitions: migo.PartitionDefinitions{{Name: "p202601", Values: "'2026-02-01'"}}},
+				},
+				NewTable: migo.Table{
+					Id:        "#/definitions/event",
+					Name:      "event",
+					Column:    []migo.Column{{Id: "created_at", Name: "created_at", Type: "date"}},
+					Partition: migo.Partition{},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE event REMOVE PARTITIONING",
+			},
+			isSuccess: true,
+			spec:      "remove partitioning",
+		},
+		{
+			input: Input{
+				CurrentTable: migo.Table{
+					Id:        "#/definitions/event",
+					Name:      "event",
+					Column:    []migo.Column{{Id: "created_at", Name: "created_at", Type: "date"}},
+					Partition: migo.Partition{Type: "range", Columns: []string{"created_at"}, Partitions: migo.PartitionDefinitions{{Name: "p202601", Values: "'2026-02-01'"}, {Name: "p202602", Values: "'2026-03-01'"}}},
+				},
+				NewTable: migo.Table{
+					Id:        "#/definitions/event",
+					Name:      "event",
+					Column:    []migo.Column{{Id: "created_at", Name: "created_at", Type: "date"}},
+					Partition: migo.Partition{Type: "range", Columns: []string{"created_at"}, Partitions: migo.PartitionDefinitions{{Name: "p202602", Values: "'2026-03-01'"}, {Name: "p202603", Values: "'2026-04-01'"}}},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE event ADD PARTITION (PARTITION p202603 VALUES LESS THAN ('2026-04-01'))",
+				"ALTER TABLE event DROP PARTITION p202601",
+			},
+			isSuccess: true,
+			spec:      "add and drop expired partitions",
+		},
+		{
+			input: Input{
+				CurrentTable: migo.Table{
+					Id:        "#/definitions/event",
+					Name:      "event",
+					Column:    []migo.Column{{Id: "created_at", Name: "created_at", Type: "date"}},
+					Partition: migo.Partition{Type: "range", Columns: []string{"created_at"}, Partitions: migo.PartitionDefinitions{{Name: "p202601", Values: "'2026-02-01'"}, {Name: "pmax", Values: "MAXVALUE"}}},
+				},
+				NewTable: migo.Table{
+					Id:        "#/definitions/event",
+					Name:      "event",
+					Column:    []migo.Column{{Id: "created_at", Name: "created_at", Type: "date"}},
+					Partition: migo.Partition{Type: "range", Columns: []string{"created_at"}, Partitions: migo.PartitionDefinitions{{Name: "p202601", Values: "'2026-02-01'"}, {Name: "p202602", Values: "'2026-03-01'"}, {Name: "pmax", Values: "MAXVALUE"}}},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE event REORGANIZE PARTITION pmax INTO (PARTITION p202602 VALUES LESS THAN ('2026-03-01'), PARTITION pmax VALUES LESS THAN MAXVALUE)",
+			},
+			isSuccess: true,
+			spec:      "reorganize partitions",
+		},
+		{
+			input: Input{
+				CurrentTable: migo.Table{
+					Id:        "#/definitions/event",
+					Name:      "event",
+					Column:    []migo.Column{{Id: "created_at", Name: "created_at", Type: "date"}},
+					Partition: migo.Partition{Type: "hash", Expression: "YEAR(created_at)", Count: 4},
+				},
+				NewTable: migo.Table{
+					Id:        "#/definitions/event",
+					Name:      "event",
+					Column:    []migo.Column{{Id: "created_at", Name: "created_at", Type: "date"}},
+					Partition: migo.Partition{Type: "hash", Expression: "YEAR(created_at)", Count: 2},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE event COALESCE PARTITION 2",
+			},
+			isSuccess: true,
+			spec:      "resize hash partitions",
+		},
 	}
 	for _, c := range cases {
 		op := migo.Operations{}
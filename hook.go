@@ -0,0 +1,168 @@
+package migo
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+const (
+	hookBeforePlan         = "before_plan"
+	hookBeforeRun          = "before_run"
+	hookAfterEachOperation = "after_each_operation"
+	hookAfterSuccess       = "after_success"
+	hookAfterFailure       = "after_failure"
+)
+
+// Hook is a SQL statement run on the database, or a local executable
+// receiving the plan as JSON on stdin.
+type Hook struct {
+	SQL     string   `json:"sql"`
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+}
+
+type Hooks struct {
+	BeforePlan         []Hook `json:"before_plan"`
+	BeforeRun          []Hook `json:"before_run"`
+	AfterEachOperation []Hook `json:"after_each_operation"`
+	AfterSuccess       []Hook `json:"after_success"`
+	AfterFailure       []Hook `json:"after_failure"`
+}
+
+type HookOperation struct {
+	Summary string `json:"summary"`
+	Query   string `json:"query"`
+}
+
+// HookPayload is the plan passed to the hooks.
+type HookPayload struct {
+	Hook        string          `json:"hook"`
+	Environment string          `json:"environment"`
+	Operations  []HookOperation `json:"operations"`
+	Warnings    []string        `json:"warnings"`
+	Operation   *HookOperation  `json:"operation,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
+
+func NewHooks(filePath string) (Hooks, error) {
+	h := Hooks{}
+	if filePath == "" {
+		return h, nil
+	}
+
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return h, errors.Wrap(err, "YAML file open error")
+	}
+	if err := yaml.Unmarshal(b, &h); err != nil {
+		return h, errors.Wrap(err, "YAML file parse error")
+	}
+
+	for _, hs := range [][]Hook{h.BeforePlan, h.BeforeRun, h.AfterEachOperation, h.AfterSuccess, h.AfterFailure} {
+		for _, hook := range hs {
+			if err := hook.validate(); err != nil {
+				return h, err
+			}
+		}
+	}
+	return h, nil
+}
+
+func NewHookOperation(op Operation) HookOperation {
+	return HookOperation{
+		Summary: op.String(),
+		Query:   op.Query(),
+	}
+}
+
+func NewHookPayload(env string, ops Operations) HookPayload {
+	p := HookPayload{
+		Environment: env,
+		Operations:  []HookOperation{},
+		Warnings:    ops.Warning,
+	}
+	for _, op := range ops.Operation {
+		p.Operations = append(p.Operations, NewHookOperation(op))
+	}
+	return p
+}
+
+func (h Hook) validate() error {
+	if (h.SQL == "") == (h.Command == "") {
+		return errors.New("hook needs either sql or command")
+	}
+	return nil
+}
+
+func (h Hook) String() string {
+	if h.SQL != "" {
+		return h.SQL
+	}
+	return h.Command
+}
+
+func (h Hook) run(mysql *sql.DB, p HookPayload) error {
+	if h.SQL != "" {
+		_, err := mysql.Exec(h.SQL)
+		return err
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "encoding plan to JSON")
+	}
+	cmd := exec.Command(h.Command, h.Args...)
+	cmd.Stdin = bytes.NewReader(b)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("MIGO_HOOK=%s", p.Hook),
+		fmt.Sprintf("MIGO_ENVIRONMENT=%s", p.Environment),
+	)
+	if p.Operation != nil {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("MIGO_OPERATION=%s", p.Operation.Summary))
+	}
+	return cmd.Run()
+}
+
+func hasSQLHook(hs []Hook) bool {
+	for _, h := range hs {
+		if h.SQL != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// runHooks runs the hooks, the SQL ones on the connection of the migration.
+// Without it, a connection is opened only when a SQL hook is given.
+func (db DB) runHooks(mysql *sql.DB, name string, hs []Hook, p HookPayload) error {
+	if len(hs) == 0 {
+		return nil
+	}
+
+	if mysql == nil && hasSQLHook(hs) {
+		var err error
+		mysql, err = db.open()
+		if err != nil {
+			return err
+		}
+		defer mysql.Close()
+	}
+
+	p.Hook = name
+	for _, h := range hs {
+		if err := h.run(mysql, p); err != nil {
+			return errors.Wrapf(err, "%s hook `%s`", name, h)
+		}
+	}
+	return nil
+}
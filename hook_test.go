@@ -0,0 +1,67 @@
+package migo_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/meta-closure/migo"
+)
+
+func TestNewHooks(t *testing.T) {
+	type Case struct {
+		input         string
+		expectedHooks migo.Hooks
+		isSuccess     bool
+		spec          string
+	}
+
+	cases := []Case{
+		{
+			input: "./test/hooks.yml",
+			expectedHooks: migo.Hooks{
+				BeforeRun: []migo.Hook{
+					{SQL: "SET GLOBAL read_only = 0"},
+				},
+				AfterEachOperation: []migo.Hook{
+					{Command: "./notify.sh", Args: []string{"migration"}},
+				},
+				AfterFailure: []migo.Hook{
+					{Command: "./notify.sh"},
+				},
+			},
+			spec:      "correct hooks",
+			isSuccess: true,
+		},
+		{
+			input:         "",
+			expectedHooks: migo.Hooks{},
+			spec:          "no hooks file",
+			isSuccess:     true,
+		},
+		{
+			input:     "./test/hooks_fail_by_empty.yml",
+			spec:      "hook without sql and command",
+			isSuccess: false,
+		},
+	}
+
+	for _, c := range cases {
+		h, err := migo.NewHooks(c.input)
+		if !c.isSuccess && err == nil {
+			t.Errorf("in %s, error is expected but null", c.spec)
+			continue
+		}
+		if c.isSuccess && err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if !reflect.DeepEqual(h, c.expectedHooks) {
+			t.Errorf("in %s, expected hooks are %+v, but actual %+v", c.spec, c.expectedHooks, h)
+		}
+	}
+}
@@ -0,0 +1,593 @@
+package migo
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// importedTable is a table read from a SQL script, which columns are kept
+// as the column blocks of the schema in the order they are declared.
+type importedTable struct {
+	Name       string
+	Columns    []string
+	Column     map[string]map[string]interface{}
+	PrimaryKey map[string][]string
+	Index      map[string][]string
+}
+
+type importedForeignKey struct {
+	Line         int
+	Name         string
+	Table        string
+	Column       string
+	TargetTable  string
+	TargetColumn string
+	Raw          map[string]interface{}
+}
+
+// sqlImporter builds the schema from the statements, reporting what it can
+// not map to the schema.
+type sqlImporter struct {
+	tables     map[string]*importedTable
+	foreignKey []importedForeignKey
+	unmapped   []string
+	line       int
+}
+
+func newImportedTable(name string) *importedTable {
+	return &importedTable{
+		Name:       name,
+		Column:     map[string]map[string]interface{}{},
+		PrimaryKey: map[string][]string{},
+		Index:      map[string][]string{},
+	}
+}
+
+func (im *sqlImporter) report(format string, args ...interface{}) {
+	im.unmapped = append(im.unmapped, fmt.Sprintf("line %d: %s", im.line, fmt.Sprintf(format, args...)))
+}
+
+// ImportSQL reads the CREATE TABLE, CREATE INDEX and ALTER TABLE statements
+// of the script into the schema, and returns it with the constructs which are
+// not mapped.
+func ImportSQL(script string) (map[string]interface{}, []string, error) {
+	stmts, err := splitSQL(script)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	im := &sqlImporter{tables: map[string]*importedTable{}}
+	for _, stmt := range stmts {
+		im.line = stmt.Line
+		if err := im.read(newSQLParser(stmt.Tokens)); err != nil {
+			return nil, nil, errors.Wrapf(err, "line %d", stmt.Line)
+		}
+	}
+	im.resolveForeignKeys()
+	return im.schema(), im.unmapped, nil
+}
+
+func (im *sqlImporter) read(p *sqlParser) error {
+	switch {
+	case p.accept("CREATE", "TABLE"):
+		return im.readCreateTable(p)
+	case p.accept("CREATE", "UNIQUE", "INDEX"):
+		return im.readCreateIndex(p, true)
+	case p.accept("CREATE", "INDEX"):
+		return im.readCreateIndex(p, false)
+	case p.accept("ALTER", "TABLE"):
+		return im.readAlterTable(p)
+	case p.is("SET"), p.is("USE"), p.is("DROP"), p.is("LOCK"), p.is("UNLOCK"),
+		p.is("INSERT"), p.is("START"), p.is("COMMIT"), p.is("BEGIN"):
+		// session settings and data of dumps are out of the schema
+		return nil
+	}
+	im.report("%s is not mapped", abbreviate(renderSQL(p.rest())))
+	return nil
+}
+
+func abbreviate(s string) string {
+	if len(s) > 40 {
+		return s[:40] + "..."
+	}
+	return s
+}
+
+func (im *sqlImporter) findTable(name string) (*importedTable, bool) {
+	t, ok := im.tables[name]
+	if !ok {
+		im.report("table %s is not found", name)
+	}
+	return t, ok
+}
+
+func (im *sqlImporter) readCreateTable(p *sqlParser) error {
+	p.accept("IF", "NOT", "EXISTS")
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	if !p.isSymbol("(") {
+		im.report("CREATE TABLE %s without column definitions is not mapped", name)
+		return nil
+	}
+	defs, err := p.group()
+	if err != nil {
+		return err
+	}
+
+	t := newImportedTable(name)
+	im.tables[name] = t
+	for _, def := range splitSQLTokens(defs) {
+		if err := im.readDefinition(t, newSQLParser(def)); err != nil {
+			return errors.Wrapf(err, "reading table %s", name)
+		}
+	}
+
+	options := []sqlToken{}
+	for !p.done() {
+		if p.accept("PARTITION", "BY") {
+			im.report("partitioning of table %s is not mapped", name)
+			break
+		}
+		options = append(options, p.next())
+	}
+	if len(options) > 0 {
+		im.report("table options %s of table %s are not mapped", abbreviate(renderSQL(options)), name)
+	}
+	return nil
+}
+
+// readDefinition reads a column or a constraint of CREATE TABLE.
+func (im *sqlImporter) readDefinition(t *importedTable, p *sqlParser) error {
+	constraint := ""
+	if p.accept("CONSTRAINT") {
+		if !p.is("PRIMARY") && !p.is("UNIQUE") && !p.is("FOREIGN") && !p.is("CHECK") {
+			name, err := p.name()
+			if err != nil {
+				return err
+			}
+			constraint = name
+		}
+	}
+
+	switch {
+	case p.accept("PRIMARY", "KEY"):
+		return im.readKey(t, p, constraint, true, false)
+	case p.accept("UNIQUE"):
+		if !p.accept("KEY") {
+			p.accept("INDEX")
+		}
+		return im.readKey(t, p, constraint, false, true)
+	case p.accept("KEY"), p.accept("INDEX"):
+		return im.readKey(t, p, constraint, false, false)
+	case p.accept("FOREIGN", "KEY"):
+		return im.readForeignKey(t, p, constraint)
+	case p.is("CHECK"):
+		im.report("check constraint %s of table %s is not mapped", abbreviate(renderSQL(p.rest())), t.Name)
+		return nil
+	case p.is("FULLTEXT"), p.is("SPATIAL"):
+		im.report("%s index of table %s is not mapped", strings.ToUpper(p.peek().Value), t.Name)
+		return nil
+	}
+	return im.readColumn(t, p)
+}
+
+// readKey reads the name and the columns of the key, where an unnamed index
+// is named after its first column as MySQL does.
+func (im *sqlImporter) readKey(t *importedTable, p *sqlParser, name string, primary, unique bool) error {
+	if !p.isSymbol("(") && !p.is("USING") {
+		n, err := p.name()
+		if err != nil {
+			return err
+		}
+		name = n
+	}
+	if p.accept("USING") {
+		p.next()
+	}
+	ts, err := p.group()
+	if err != nil {
+		return err
+	}
+	cs, err := im.keyColumnNames(t, name, ts)
+	if err != nil {
+		return err
+	}
+	im.addKey(t, name, cs, primary, unique)
+	return nil
+}
+
+// keyColumnNames reads the column list of the key, reporting the prefix
+// lengths and the orders which are not mapped.
+func (im *sqlImporter) keyColumnNames(t *importedTable, name string, ts []sqlToken) ([]string, error) {
+	cs, ignored, err := sqlColumnNames(ts)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" && len(cs) > 0 {
+		name = cs[0]
+	}
+	for _, i := range ignored {
+		im.report("prefix length or order of %s in key %s of table %s is not mapped", i, name, t.Name)
+	}
+	return cs, nil
+}
+
+func (im *sqlImporter) addKey(t *importedTable, name string, cs []string, primary, unique bool) {
+	for _, c := range cs {
+		if _, ok := t.Column[c]; !ok {
+			im.report("column %s of key in table %s is not found", c, t.Name)
+			return
+		}
+	}
+
+	switch {
+	case primary:
+		if name == "" || strings.EqualFold(name, primaryKeyIndexName) {
+			name = fmt.Sprintf("%s_pk", t.Name)
+		}
+		t.PrimaryKey[name] = cs
+	case unique && len(cs) == 1:
+		if name != "" && name != cs[0] {
+			im.report("name %s of unique key on column %s in table %s is not mapped", name, cs[0], t.Name)
+		}
+		t.Column[cs[0]]["unique"] = true
+	default:
+		if name == "" {
+			name = cs[0]
+		}
+		if unique {
+			im.report("unique index %s of table %s on multiple columns is imported as index, dropping its uniqueness", name, t.Name)
+		}
+		t.Index[name] = cs
+	}
+}
+
+func (im *sqlImporter) readForeignKey(t *importedTable, p *sqlParser, name string) error {
+	if !p.isSymbol("(") {
+		n, err := p.name()
+		if err != nil {
+			return err
+		}
+		name = n
+	}
+	ts, err := p.group()
+	if err != nil {
+		return err
+	}
+	cs, _, err := sqlColumnNames(ts)
+	if err != nil {
+		return err
+	}
+	if !p.accept("REFERENCES") {
+		return errors.New("REFERENCES is expected")
+	}
+	target, err := p.name()
+	if err != nil {
+		return err
+	}
+	ts, err = p.group()
+	if err != nil {
+		return err
+	}
+	targets, _, err := sqlColumnNames(ts)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		n := 1
+		for _, fk := range im.foreignKey {
+			if fk.Table == t.Name {
+				n++
+			}
+		}
+		name = fmt.Sprintf("%s_ibfk_%d", t.Name, n)
+	}
+	if len(cs) != 1 || len(targets) != 1 {
+		im.report("foreign key %s of table %s on multiple columns is not mapped", name, t.Name)
+		return nil
+	}
+
+	fk := importedForeignKey{
+		Line:         im.line,
+		Name:         name,
+		Table:        t.Name,
+		Column:       cs[0],
+		TargetTable:  target,
+		TargetColumn: targets[0],
+		Raw:          map[string]interface{}{"name": name},
+	}
+	for p.accept("ON") {
+		event := strings.ToLower(p.next().Value)
+		switch {
+		case p.accept("CASCADE"):
+			fk.Raw[event+"_cascade"] = true
+		case p.accept("RESTRICT"), p.accept("NO", "ACTION"):
+		case p.accept("SET", "NULL"):
+			im.report("ON %s SET NULL of foreign key %s is not mapped", strings.ToUpper(event), name)
+		case p.accept("SET", "DEFAULT"):
+			im.report("ON %s SET DEFAULT of foreign key %s is not mapped", strings.ToUpper(event), name)
+		default:
+			im.report("ON %s %s of foreign key %s is not mapped", strings.ToUpper(event), renderSQL(p.rest()), name)
+		}
+	}
+	im.foreignKey = append(im.foreignKey, fk)
+	return nil
+}
+
+// readColumn reads the column definition into the column block.
+func (im *sqlImporter) readColumn(t *importedTable, p *sqlParser) error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	typ, err := readSQLColumnType(p)
+	if err != nil {
+		return errors.Wrapf(err, "reading type of column %s", name)
+	}
+
+	c := map[string]interface{}{"name": name, "type": typ}
+	if old, ok := t.Column[name]; ok {
+		// MODIFY keeps the unique key added to the column before
+		if old["unique"] != nil {
+			c["unique"] = old["unique"]
+		}
+	} else {
+		t.Columns = append(t.Columns, name)
+	}
+	t.Column[name] = c
+
+	for !p.done() {
+		switch {
+		case p.accept("NOT", "NULL"):
+			c["not_null"] = true
+		case p.accept("NULL"):
+		case p.accept("DEFAULT"):
+			c["default"] = readSQLDefault(p)
+		case p.accept("AUTO_INCREMENT"):
+			c["auto_increment"] = true
+		case p.accept("UNIQUE"):
+			p.accept("KEY")
+			c["unique"] = true
+		case p.accept("PRIMARY", "KEY"), p.accept("KEY"):
+			im.addKey(t, "", []string{name}, true, false)
+		case p.accept("COMMENT"):
+			c["comment"] = p.next().Value
+		case p.accept("CHARACTER", "SET"), p.accept("CHARSET"):
+			c["charset"] = p.next().Value
+		case p.accept("COLLATE"):
+			c["collation"] = p.next().Value
+		case p.accept("ON", "UPDATE"):
+			p.next()
+			if p.isSymbol("(") {
+				p.group()
+			}
+			c["auto_update"] = true
+		case p.accept("GENERATED", "ALWAYS", "AS"), p.accept("AS"):
+			ts, err := p.group()
+			if err != nil {
+				return err
+			}
+			c["expression"] = renderSQL(ts)
+		case p.accept("VIRTUAL"):
+			c["storage"] = storageVirtual
+		case p.accept("STORED"), p.accept("PERSISTENT"):
+			c["storage"] = storageStored
+		default:
+			im.report("%s of column %s in table %s is not mapped", abbreviate(renderSQL(p.rest())), name, t.Name)
+		}
+	}
+
+	// migo gives the current timestamp of a NOT NULL or auto updated datetime column implicitly
+	e, _ := c["default"].(map[string]interface{})
+	ct, err := ParseColumnType(typ)
+	if s, ok := e["expression"].(string); ok && err == nil && ct.isDatetime() && isCurrentTimestamp(s) &&
+		(c["not_null"] != nil || c["auto_update"] != nil) {
+		delete(c, "default")
+	}
+	return nil
+}
+
+func readSQLColumnType(p *sqlParser) (string, error) {
+	t := p.next()
+	if t.Kind != sqlWord {
+		return "", fmt.Errorf("type is expected, but %q", t.Value)
+	}
+	typ := strings.ToLower(t.Value)
+	if typ == "double" {
+		p.accept("PRECISION")
+	}
+	if p.isSymbol("(") {
+		ts, err := p.group()
+		if err != nil {
+			return "", err
+		}
+		typ += "(" + strings.Replace(renderSQL(ts), ", ", ",", -1) + ")"
+	}
+	for _, w := range []string{"UNSIGNED", "ZEROFILL"} {
+		if p.accept(w) {
+			typ += " " + strings.ToLower(w)
+		}
+	}
+	p.accept("SIGNED")
+	return typ, nil
+}
+
+// readSQLDefault reads the default value in the form of the column block,
+// where NULL is nil.
+func readSQLDefault(p *sqlParser) interface{} {
+	t := p.next()
+	switch {
+	case t.Kind == sqlString:
+		return t.Value
+	case t.Kind == sqlSymbol && t.Value == "-" && p.peek().Kind == sqlNumber:
+		t = p.next()
+		t.Value = "-" + t.Value
+		fallthrough
+	case t.Kind == sqlNumber:
+		f, err := strconv.ParseFloat(t.Value, 64)
+		if err != nil {
+			return t.Value
+		}
+		return f
+	case t.Kind == sqlSymbol && t.Value == "(":
+		p.pos--
+		ts, _ := p.group()
+		return map[string]interface{}{"expression": renderSQL(ts)}
+	case strings.EqualFold(t.Value, "NULL"):
+		return nil
+	case strings.EqualFold(t.Value, "TRUE"), strings.EqualFold(t.Value, "FALSE"):
+		return strings.EqualFold(t.Value, "TRUE")
+	}
+
+	e := []sqlToken{t}
+	if p.isSymbol("(") {
+		start := p.pos
+		p.group()
+		e = append(e, p.tokens[start:p.pos]...)
+	}
+	return map[string]interface{}{"expression": renderSQL(e)}
+}
+
+func (im *sqlImporter) readCreateIndex(p *sqlParser, unique bool) error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	if p.accept("USING") {
+		p.next()
+	}
+	if !p.accept("ON") {
+		return errors.New("ON is expected")
+	}
+	table, err := p.name()
+	if err != nil {
+		return err
+	}
+	ts, err := p.group()
+	if err != nil {
+		return err
+	}
+	t, ok := im.findTable(table)
+	if !ok {
+		return nil
+	}
+	cs, err := im.keyColumnNames(t, name, ts)
+	if err != nil {
+		return err
+	}
+	im.addKey(t, name, cs, false, unique)
+	return nil
+}
+
+func (im *sqlImporter) readAlterTable(p *sqlParser) error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	t, ok := im.findTable(name)
+	if !ok {
+		return nil
+	}
+
+	for _, spec := range splitSQLTokens(p.rest()) {
+		sp := newSQLParser(spec)
+		switch {
+		case sp.accept("ADD", "COLUMN"), sp.accept("MODIFY", "COLUMN"), sp.accept("MODIFY"):
+			err = im.readColumn(t, sp)
+		case sp.accept("ADD"):
+			err = im.readDefinition(t, sp)
+		default:
+			im.report("ALTER TABLE %s %s is not mapped", name, abbreviate(renderSQL(spec)))
+		}
+		if err != nil {
+			return errors.Wrapf(err, "altering table %s", name)
+		}
+	}
+	return nil
+}
+
+// resolveForeignKeys puts the foreign keys in the column blocks, after all
+// the tables are read as ALTER TABLE may add them before the target.
+func (im *sqlImporter) resolveForeignKeys() {
+	for _, fk := range im.foreignKey {
+		im.line = fk.Line
+		c, ok := im.tables[fk.Table].Column[fk.Column]
+		if !ok {
+			im.report("column %s of foreign key %s is not found", fk.Column, fk.Name)
+			continue
+		}
+		target, ok := im.tables[fk.TargetTable]
+		if !ok {
+			im.report("target table %s of foreign key %s is not found", fk.TargetTable, fk.Name)
+			continue
+		}
+		if _, ok := target.Column[fk.TargetColumn]; !ok {
+			im.report("target column %s of foreign key %s is not found", fk.TargetColumn, fk.Name)
+			continue
+		}
+		fk.Raw["target_table"] = definitonsID(fk.TargetTable)
+		fk.Raw["target_column"] = fk.TargetColumn
+		c["foreign_key"] = fk.Raw
+	}
+}
+
+// schema returns the hyper-schema, where the definitions and properties are
+// keyed by the table and column names.
+func (im *sqlImporter) schema() map[string]interface{} {
+	ds := map[string]interface{}{}
+	for name, t := range im.tables {
+		table := map[string]interface{}{"name": name}
+		if len(t.PrimaryKey) > 0 {
+			table["primary_key"] = t.PrimaryKey
+		}
+		if len(t.Index) > 0 {
+			table["index"] = t.Index
+		}
+
+		ps := map[string]interface{}{}
+		for _, c := range t.Columns {
+			ps[c] = map[string]interface{}{"column": t.Column[c]}
+		}
+		ds[name] = map[string]interface{}{
+			"type":       "object",
+			"title":      name,
+			"table":      table,
+			"properties": ps,
+		}
+	}
+	return map[string]interface{}{"definitions": ds}
+}
+
+// Import prints the schema read from the SQL script, and the constructs not
+// mapped as warnings to the report.
+func Import(op ImportOption, w, report io.Writer) error {
+	b, err := ioutil.ReadFile(op.SQLFile)
+	if err != nil {
+		return errors.Wrap(err, "SQL file open error")
+	}
+	s, unmapped, err := ImportSQL(string(b))
+	if err != nil {
+		return errors.Wrapf(err, "parsing %s", op.SQLFile)
+	}
+
+	y, err := yaml.Marshal(s)
+	if err != nil {
+		return errors.Wrap(err, "converting schema to YAML")
+	}
+	if _, err := w.Write(y); err != nil {
+		return err
+	}
+	for _, u := range unmapped {
+		fmt.Fprintf(report, "WARNING: %s\n", u)
+	}
+	return nil
+}
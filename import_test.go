@@ -0,0 +1,97 @@
+package migo_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/meta-closure/migo"
+)
+
+func TestImportSQL(t *testing.T) {
+	type Case struct {
+		input            string
+		expectedDDL      string
+		expectedUnmapped []string
+		isSuccess        bool
+		spec             string
+	}
+
+	cases := []Case{
+		{
+			input: "./test/import_test.sql",
+			expectedDDL: "CREATE TABLE post (editor_id int(11) unsigned,id int NOT NULL,status enum('draft','published') NOT NULL DEFAULT 'draft',title varchar(255),user_id int(11) unsigned NOT NULL,PRIMARY KEY post_pk (id),INDEX idx_title (title))ENGINE=innoDB;\n" +
+				"CREATE TABLE user (code varchar(8) NOT NULL UNIQUE,created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,deleted_at datetime DEFAULT NULL,email varchar(255) NOT NULL UNIQUE COMMENT 'login; address',id int(11) unsigned AUTO_INCREMENT NOT NULL,name varchar(64) DEFAULT 'anonymous',score decimal(10,2) DEFAULT -1.5,updated_at datetime NOT NULL ON UPDATE CURRENT_TIMESTAMP DEFAULT CURRENT_TIMESTAMP,PRIMARY KEY user_pk (id),INDEX idx_name (name),INDEX uk_name_score (name,score))ENGINE=innoDB;\n" +
+				"ALTER TABLE post ADD CONSTRAINT fk_post_editor FOREIGN KEY (editor_id) REFERENCES user (id) ON DELETE CASCADE;\n" +
+				"ALTER TABLE post ADD CONSTRAINT fk_post_user FOREIGN KEY (user_id) REFERENCES user (id) ON DELETE CASCADE;\n",
+			expectedUnmapped: []string{
+				"line 4: name uk_code of unique key on column code in table user is not mapped",
+				"line 4: unique index uk_name_score of table user on multiple columns is imported as index, dropping its uniqueness",
+				"line 4: table options ENGINE = InnoDB DEFAULT CHARSET = utf8mb... of table user are not mapped",
+				"line 20: FULLTEXT index of table post is not mapped",
+				"line 20: check constraint CHECK(id > 0) of table post is not mapped",
+				"line 20: partitioning of table post is not mapped",
+				"line 20: table options ENGINE = InnoDB of table post are not mapped",
+				"line 32: prefix length or order of title(10) in key idx_title of table post is not mapped",
+				"line 34: ON UPDATE SET NULL of foreign key fk_post_editor is not mapped",
+				"line 35: CREATE VIEW recent AS SELECT * FROM post is not mapped",
+			},
+			isSuccess: true,
+			spec:      "dumped schema",
+		},
+		{
+			input:     "./test/import_test_fail_by_quote.sql",
+			isSuccess: false,
+			spec:      "quote is not closed",
+		},
+	}
+
+	for _, c := range cases {
+		b, err := ioutil.ReadFile(c.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, unmapped, err := migo.ImportSQL(string(b))
+		if !c.isSuccess && err == nil {
+			t.Errorf("in %s, error is expected but null", c.spec)
+			continue
+		}
+		if c.isSuccess && err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if !reflect.DeepEqual(unmapped, c.expectedUnmapped) {
+			t.Errorf("in %s, expected unmapped constructs are %v, but actual %v", c.spec, c.expectedUnmapped, unmapped)
+		}
+
+		y, err := yaml.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := ioutil.TempFile("", "migo-import-*.yml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(f.Name())
+		f.Write(y)
+		f.Close()
+
+		ddl := &bytes.Buffer{}
+		err = migo.DDL(migo.DDLOption{Migrate: migo.MigrateOption{SchemaFile: f.Name(), FormatType: "yaml"}}, ddl)
+		if err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+			continue
+		}
+		if ddl.String() != c.expectedDDL {
+			t.Errorf("in %s, expected DDL is %q, but actual %q", c.spec, c.expectedDDL, ddl.String())
+		}
+	}
+}
@@ -64,7 +64,16 @@ func (k Key) isUpdatedFrom(target Key) (bool, error) {
 	if k.Name != target.Name {
 		return false, errors.New("the target key name is wrong")
 	}
-	return !reflect.DeepEqual(k, target), nil
+	return !reflect.DeepEqual(k.normalize(), target.normalize()), nil
+}
+
+func (k Key) normalize() Key {
+	cs := Columns{}
+	for _, c := range k.Target {
+		cs = append(cs, c.normalize())
+	}
+	k.Target = cs
+	return k
 }
 
 func (k Key) queryAsPrimaryKey() string {
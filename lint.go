@@ -0,0 +1,454 @@
+package migo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	hschema "github.com/lestrrat/go-jshschema"
+	schema "github.com/lestrrat/go-jsschema"
+	"github.com/pkg/errors"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityOff     = "off"
+
+	maxIdentifierLength = 64
+)
+
+// Problem is a mistake in the schema found by the linter, located by the
+// JSON pointer to the schema object.
+type Problem struct {
+	Pointer  string `json:"pointer"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+type Problems []Problem
+
+func (p Problems) Len() int {
+	return len(p)
+}
+
+func (p Problems) Less(i, j int) bool {
+	if p[i].Pointer != p[j].Pointer {
+		return p[i].Pointer < p[j].Pointer
+	}
+	return p[i].Rule < p[j].Rule
+}
+
+func (p Problems) Swap(i, j int) {
+	p[i], p[j] = p[j], p[i]
+}
+
+func (p Problems) HasError() bool {
+	for _, problem := range p {
+		if problem.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// lintRule checks a state, reporting the problems with the severity of the rule.
+type lintRule struct {
+	name     string
+	severity string
+	enabled  bool
+	check    func(s State, c LintRuleConfig) []Problem
+}
+
+// lintRules are the rules of the linter. The convention rules are disabled
+// unless they are enabled in the rules file.
+var lintRules = []lintRule{
+	{"no-primary-key", SeverityWarning, true, lintPrimaryKey},
+	{"duplicate-column-name", SeverityError, true, lintDuplicateColumnName},
+	{"identifier-too-long", SeverityError, true, lintIdentifierLength},
+	{"reserved-word", SeverityWarning, true, lintReservedWord},
+	{"auto-increment-not-key", SeverityError, true, lintAutoIncrement},
+	{"foreign-key-type-mismatch", SeverityError, true, lintForeignKeyType},
+	{"foreign-key-not-unique", SeverityError, true, lintForeignKeyTarget},
+	{"snake-case", SeverityWarning, false, lintSnakeCase},
+	{"name-prefix", SeverityWarning, false, lintNamePrefix},
+	{"timestamps", SeverityWarning, false, lintTimestamps},
+	{"foreign-key-indexed", SeverityWarning, false, lintForeignKeyIndexed},
+	{"no-float-money", SeverityWarning, false, lintFloatMoney},
+}
+
+func findLintRule(name string) (lintRule, error) {
+	for _, r := range lintRules {
+		if r.name == name {
+			return r, nil
+		}
+	}
+	return lintRule{}, fmt.Errorf("lint rule %s is not found", name)
+}
+
+// Lint returns the problems in the state built from the schema, following
+// the rules file and the lint_ignore of the tables.
+func Lint(s State, conf LintConfig) Problems {
+	ps := Problems{}
+	for _, r := range lintRules {
+		c, ok := conf.Rules[r.name]
+		if !ok && !r.enabled || c.Severity == SeverityOff {
+			continue
+		}
+		severity := r.severity
+		if c.Severity != "" {
+			severity = c.Severity
+		}
+
+		for _, p := range r.check(s, c) {
+			p.Rule, p.Severity = r.name, severity
+			if !s.isLintIgnored(p) {
+				ps = append(ps, p)
+			}
+		}
+	}
+	sort.Sort(ps)
+	return ps
+}
+
+func tablePointer(t Table) string {
+	return strings.TrimPrefix(t.Id, "#")
+}
+
+func columnPointer(t Table, c Column) string {
+	return fmt.Sprintf("%s/properties/%s", tablePointer(t), c.Id)
+}
+
+func lintPrimaryKey(s State, _ LintRuleConfig) []Problem {
+	ps := []Problem{}
+	for _, t := range s.Tables {
+		if len(t.PrimaryKey) == 0 {
+			ps = append(ps, Problem{
+				Pointer: tablePointer(t) + "/table",
+				Message: fmt.Sprintf("table %s has no primary key", t.Name),
+			})
+		}
+	}
+	return ps
+}
+
+func lintDuplicateColumnName(s State, _ LintRuleConfig) []Problem {
+	ps := []Problem{}
+	for _, t := range s.Tables {
+		names := map[string]bool{}
+		for _, c := range t.Column {
+			if names[c.Name] {
+				ps = append(ps, Problem{
+					Pointer: columnPointer(t, c) + "/column/name",
+					Message: fmt.Sprintf("column name %s is duplicated in table %s", c.Name, t.Name),
+				})
+			}
+			names[c.Name] = true
+		}
+	}
+	return ps
+}
+
+type identifier struct {
+	pointer string
+	name    string
+}
+
+func (s State) identifiers() []identifier {
+	is := []identifier{}
+	for _, t := range s.Tables {
+		p := tablePointer(t) + "/table"
+		is = append(is, identifier{p + "/name", t.Name})
+		for _, c := range t.Column {
+			is = append(is, identifier{columnPointer(t, c) + "/column/name", c.Name})
+		}
+		for _, k := range t.PrimaryKey {
+			is = append(is, identifier{p + "/primary_key/" + k.Name, k.Name})
+		}
+		for _, k := range t.Index {
+			is = append(is, identifier{p + "/index/" + k.Name, k.Name})
+		}
+		for _, tr := range t.Trigger {
+			is = append(is, identifier{p + "/trigger/" + tr.Name, tr.Name})
+		}
+		for _, ch := range t.Check {
+			is = append(is, identifier{t.checkPointer(ch), ch.Name})
+		}
+	}
+	for _, fk := range s.ForeignKey {
+		is = append(is, identifier{columnPointer(fk.SourceTable, fk.SourceColumn) + "/column/foreign_key/name", fk.Name})
+	}
+	return is
+}
+
+// checkPointer returns the pointer to the column which the check is derived from.
+func (t Table) checkPointer(ch Check) string {
+	for _, c := range t.Column {
+		for _, kind := range checkKinds {
+			if checkName(t, c, kind) == ch.Name {
+				return columnPointer(t, c)
+			}
+		}
+	}
+	return tablePointer(t) + "/table"
+}
+
+func lintIdentifierLength(s State, _ LintRuleConfig) []Problem {
+	ps := []Problem{}
+	for _, i := range s.identifiers() {
+		if len(i.name) > maxIdentifierLength {
+			ps = append(ps, Problem{
+				Pointer: i.pointer,
+				Message: fmt.Sprintf("identifier %s is longer than %d characters", i.name, maxIdentifierLength),
+			})
+		}
+	}
+	return ps
+}
+
+func lintReservedWord(s State, _ LintRuleConfig) []Problem {
+	ps := []Problem{}
+	for _, i := range s.identifiers() {
+		if reservedWords[strings.ToUpper(i.name)] {
+			ps = append(ps, Problem{
+				Pointer: i.pointer,
+				Message: fmt.Sprintf("identifier %s is a reserved word", i.name),
+			})
+		}
+	}
+	return ps
+}
+
+// isKeyHead reports whether the column is the first column of a key, which
+// MySQL requires for auto_increment.
+func (t Table) isKeyHead(c Column) bool {
+	if c.Unique {
+		return true
+	}
+	for _, k := range append(append(Keys{}, t.PrimaryKey...), t.Index...) {
+		if len(k.Target) > 0 && k.Target[0].Name == c.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func lintAutoIncrement(s State, _ LintRuleConfig) []Problem {
+	ps := []Problem{}
+	for _, t := range s.Tables {
+		for _, c := range t.Column {
+			if c.AutoIncrement && !t.isKeyHead(c) {
+				ps = append(ps, Problem{
+					Pointer: columnPointer(t, c) + "/column/auto_increment",
+					Message: fmt.Sprintf("auto_increment column %s is not the first column of any key in table %s", c.Name, t.Name),
+				})
+			}
+		}
+	}
+	return ps
+}
+
+func lintForeignKeyType(s State, _ LintRuleConfig) []Problem {
+	ps := []Problem{}
+	for _, fk := range s.ForeignKey {
+		st := fk.SourceColumn.columnType().normalize().String()
+		tt := fk.TargetColumn.columnType().normalize().String()
+		if st != tt {
+			ps = append(ps, Problem{
+				Pointer: columnPointer(fk.SourceTable, fk.SourceColumn) + "/column/foreign_key",
+				Message: fmt.Sprintf("foreign key %s column type %s differs from target column type %s", fk.Name, st, tt),
+			})
+		}
+	}
+	return ps
+}
+
+func lintForeignKeyTarget(s State, _ LintRuleConfig) []Problem {
+	ps := []Problem{}
+	for _, fk := range s.ForeignKey {
+		t, c := fk.TargetTable, fk.TargetColumn
+		unique := c.Unique
+		for _, k := range t.PrimaryKey {
+			unique = unique || (len(k.Target) == 1 && k.Target[0].Name == c.Name)
+		}
+		if !unique {
+			ps = append(ps, Problem{
+				Pointer: columnPointer(fk.SourceTable, fk.SourceColumn) + "/column/foreign_key/target_column",
+				Message: fmt.Sprintf("foreign key %s refers to non-unique column %s in table %s", fk.Name, c.Name, t.Name),
+			})
+		}
+	}
+	return ps
+}
+
+func schemaProblem(pointer string, err error) Problem {
+	return Problem{Pointer: pointer, Severity: SeverityError, Rule: "schema", Message: err.Error()}
+}
+
+// readProblems reads the table like read, but goes on after a column, key or
+// partition which can not be read, and returns them as problems at their pointers.
+func (t *Table) readProblems(schema *schema.Schema, op SchemaOption, pointer string) []Problem {
+	m, ok := schema.Extras["table"].(map[string]interface{})
+	if !ok {
+		return []Problem{schemaProblem(pointer+"/table", errors.New("convert from interface{} to map[string]interface{}"))}
+	}
+	ps := []Problem{}
+	if err := t.setName(m["name"]); err != nil {
+		ps = append(ps, schemaProblem(pointer+"/table/name", err))
+	}
+
+	for k, s := range schema.Properties {
+		if hasNotColumn(*s) {
+			continue
+		}
+		c := NewColumn(k)
+		if err := c.read(*s, isRequired(schema, k), op); err != nil {
+			ps = append(ps, schemaProblem(fmt.Sprintf("%s/properties/%s/column", pointer, escapePointer(k)), err))
+			continue
+		}
+		t.Column = append(t.Column, c)
+	}
+
+	for _, section := range []string{"primary_key", "index"} {
+		if m[section] == nil {
+			continue
+		}
+		keys, ok := m[section].(map[string]interface{})
+		if !ok {
+			ps = append(ps, schemaProblem(pointer+"/table/"+section, errors.New("fail to convert type to map[string]interface{}")))
+			continue
+		}
+		for k, v := range keys {
+			if _, err := targetList(*t, v); err != nil {
+				ps = append(ps, schemaProblem(fmt.Sprintf("%s/table/%s/%s", pointer, section, escapePointer(k)), err))
+			}
+		}
+	}
+
+	if _, err := t.findPartition(m); err != nil {
+		ps = append(ps, schemaProblem(pointer+"/table/partition", err))
+	}
+	return ps
+}
+
+// FindSchemaProblems reads the tables and foreign keys of the schema, and
+// returns every column, key, partition and foreign key which can not be read
+// as a problem at its pointer, instead of the first error of NewStateFromSchema.
+func FindSchemaProblems(root *hschema.HyperSchema, op SchemaOption) Problems {
+	var err error
+	op.typeMapping, err = NewTypeMapping(op.Dialect, op.TypeMappingFile)
+	if err != nil {
+		return Problems{schemaProblem("", errors.Wrap(err, "reading type mapping"))}
+	}
+
+	sections := []struct {
+		name    string
+		id      func(string) string
+		schemas map[string]*schema.Schema
+	}{
+		{"definitions", definitonsID, root.Definitions},
+		{"properties", propertiesID, root.Properties},
+	}
+
+	ps := Problems{}
+	s := NewState()
+	for _, section := range sections {
+		for k, v := range section.schemas {
+			if hasNotTable(v) {
+				continue
+			}
+			t := NewTable(section.id(k))
+			ps = append(ps, t.readProblems(v, op, fmt.Sprintf("/%s/%s", section.name, escapePointer(k)))...)
+			s.Tables = append(s.Tables, *t)
+		}
+	}
+
+	for _, section := range sections {
+		for k, v := range section.schemas {
+			t, err := s.findTableWithID(section.id(k))
+			if err != nil {
+				continue
+			}
+			for id, column := range v.Properties {
+				c, err := t.findColumnWithID(id)
+				if err != nil || !hasForeignKey(*column) {
+					continue
+				}
+				pointer := fmt.Sprintf("/%s/%s/properties/%s/column/foreign_key", section.name, escapePointer(k), escapePointer(id))
+				fk := NewForeignKey(t, c)
+				if err := fk.read(*column); err != nil {
+					ps = append(ps, schemaProblem(pointer, err))
+					continue
+				}
+				if err := fk.resolve(s); err != nil {
+					ps = append(ps, schemaProblem(pointer, err))
+				}
+			}
+		}
+	}
+	sort.Sort(ps)
+	return ps
+}
+
+// Validate lints the schema without connecting to the database. The parts of
+// the schema which can not be read are reported as problems at their pointers,
+// and the other errors reading the schema as a problem at the root.
+func Validate(op ValidateOption) (Problems, error) {
+	conf, err := NewLintConfig(op.RulesFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading lint rules")
+	}
+
+	h, err := ReadSchema(op.Migrate)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing hyper-schema")
+	}
+
+	ps := FindMetaSchemaProblems(h)
+	schemaProblems := FindSchemaProblems(h, op.Migrate.Schema)
+	ps = append(ps, schemaProblems...)
+
+	// the rules are checked unless the state can not be built at all
+	s, err := readState(h, op.Migrate.Schema)
+	if err != nil {
+		// the parts which can not be read are already reported at their pointers
+		if len(schemaProblems) == 0 {
+			ps = append(ps, Problem{Pointer: "", Severity: SeverityError, Rule: "schema", Message: err.Error()})
+		}
+	} else {
+		ps = append(ps, Lint(s, conf)...)
+	}
+	sort.Sort(ps)
+	return ps, nil
+}
+
+var reservedWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`
+		ACCESSIBLE ADD ALL ALTER ANALYZE AND AS ASC ASENSITIVE BEFORE BETWEEN BIGINT BINARY BLOB BOTH BY
+		CALL CASCADE CASE CHANGE CHAR CHARACTER CHECK COLLATE COLUMN CONDITION CONSTRAINT CONTINUE CONVERT
+		CREATE CROSS CUBE CUME_DIST CURRENT_DATE CURRENT_TIME CURRENT_TIMESTAMP CURRENT_USER CURSOR DATABASE
+		DATABASES DAY_HOUR DAY_MICROSECOND DAY_MINUTE DAY_SECOND DEC DECIMAL DECLARE DEFAULT DELAYED DELETE
+		DENSE_RANK DESC DESCRIBE DETERMINISTIC DISTINCT DISTINCTROW DIV DOUBLE DROP DUAL EACH ELSE ELSEIF
+		EMPTY ENCLOSED ESCAPED EXCEPT EXISTS EXIT EXPLAIN FALSE FETCH FIRST_VALUE FLOAT FLOAT4 FLOAT8 FOR
+		FORCE FOREIGN FROM FULLTEXT FUNCTION GENERATED GET GRANT GROUP GROUPING GROUPS HAVING HIGH_PRIORITY
+		HOUR_MICROSECOND HOUR_MINUTE HOUR_SECOND IF IGNORE IN INDEX INFILE INNER INOUT INSENSITIVE INSERT
+		INT INT1 INT2 INT3 INT4 INT8 INTEGER INTERVAL INTO IO_AFTER_GTIDS IO_BEFORE_GTIDS IS ITERATE JOIN
+		JSON_TABLE KEY KEYS KILL LAG LAST_VALUE LATERAL LEAD LEADING LEAVE LEFT LIKE LIMIT LINEAR LINES
+		LOAD LOCALTIME LOCALTIMESTAMP LOCK LONG LONGBLOB LONGTEXT LOOP LOW_PRIORITY MASTER_BIND
+		MASTER_SSL_VERIFY_SERVER_CERT MATCH MAXVALUE MEDIUMBLOB MEDIUMINT MEDIUMTEXT MIDDLEINT
+		MINUTE_MICROSECOND MINUTE_SECOND MOD MODIFIES NATURAL NOT NO_WRITE_TO_BINLOG NTH_VALUE NTILE NULL
+		NUMERIC OF ON OPTIMIZE OPTIMIZER_COSTS OPTION OPTIONALLY OR ORDER OUT OUTER OUTFILE OVER PARTITION
+		PERCENT_RANK PRECISION PRIMARY PROCEDURE PURGE RANGE RANK READ READS READ_WRITE REAL RECURSIVE
+		REFERENCES REGEXP RELEASE RENAME REPEAT REPLACE REQUIRE RESIGNAL RESTRICT RETURN REVOKE RIGHT RLIKE
+		ROW ROWS ROW_NUMBER SCHEMA SCHEMAS SECOND_MICROSECOND SELECT SENSITIVE SEPARATOR SET SHOW SIGNAL
+		SMALLINT SPATIAL SPECIFIC SQL SQLEXCEPTION SQLSTATE SQLWARNING SQL_BIG_RESULT SQL_CALC_FOUND_ROWS
+		SQL_SMALL_RESULT SSL STARTING STORED STRAIGHT_JOIN SYSTEM TABLE TERMINATED THEN TINYBLOB TINYINT
+		TINYTEXT TO TRAILING TRIGGER TRUE UNDO UNION UNIQUE UNLOCK UNSIGNED UPDATE USAGE USE USING UTC_DATE
+		UTC_TIME UTC_TIMESTAMP VALUES VARBINARY VARCHAR VARCHARACTER VARYING VIRTUAL WHEN WHERE WHILE WINDOW
+		WITH WRITE XOR YEAR_MONTH ZEROFILL`) {
+		reservedWords[w] = true
+	}
+}
@@ -0,0 +1,83 @@
+package migo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+// Write prints the problems in the format, where the SARIF results are located
+// in the schema file by the JSON pointer.
+func (p Problems) Write(w io.Writer, format, schemaFile string) error {
+	switch format {
+	case "", FormatText:
+		for _, problem := range p {
+			fmt.Fprintf(w, "%s: %s: %s (%s)\n", strings.ToUpper(problem.Severity), problem.Pointer, problem.Message, problem.Rule)
+		}
+		return nil
+	case FormatJSON:
+		return writeJSON(w, p)
+	case FormatSARIF:
+		return writeJSON(w, p.sarif(schemaFile))
+	}
+	return fmt.Errorf("format %s is invalid", format)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+func (p Problems) sarif(schemaFile string) map[string]interface{} {
+	// meta-schema and schema are the rules of the problems found by Validate in the schema itself
+	rules := []map[string]interface{}{{"id": "meta-schema"}, {"id": "schema"}}
+	for _, r := range lintRules {
+		rules = append(rules, map[string]interface{}{"id": r.name})
+	}
+
+	results := []map[string]interface{}{}
+	for _, problem := range p {
+		results = append(results, map[string]interface{}{
+			"ruleId":  problem.Rule,
+			"level":   problem.Severity,
+			"message": map[string]interface{}{"text": problem.Message},
+			"locations": []map[string]interface{}{
+				{
+					"physicalLocation": map[string]interface{}{
+						"artifactLocation": map[string]interface{}{"uri": schemaFile},
+					},
+					"logicalLocations": []map[string]interface{}{
+						{"fullyQualifiedName": problem.Pointer},
+					},
+				},
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []map[string]interface{}{
+			{
+				"tool": map[string]interface{}{
+					"driver": map[string]interface{}{
+						"name":  "migo",
+						"rules": rules,
+					},
+				},
+				"results": results,
+			},
+		},
+	}
+}
@@ -0,0 +1,233 @@
+package migo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// LintRuleConfig enables a lint rule with the severity, or disables it with off.
+type LintRuleConfig struct {
+	Severity string                 `json:"severity"`
+	Options  map[string]interface{} `json:"options"`
+	pattern  *regexp.Regexp
+}
+
+type LintConfig struct {
+	Rules map[string]LintRuleConfig `json:"rules"`
+}
+
+func NewLintConfig(filePath string) (LintConfig, error) {
+	conf := LintConfig{}
+	if filePath == "" {
+		return conf, nil
+	}
+
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return conf, errors.Wrap(err, "YAML file open error")
+	}
+	if err := yaml.Unmarshal(b, &conf); err != nil {
+		return conf, errors.Wrap(err, "YAML file parse error")
+	}
+
+	for name, c := range conf.Rules {
+		if _, err := findLintRule(name); err != nil {
+			return conf, err
+		}
+		switch c.Severity {
+		case "", SeverityError, SeverityWarning, SeverityOff:
+		default:
+			return conf, fmt.Errorf("severity %s of lint rule %s is invalid", c.Severity, name)
+		}
+		if name == "no-float-money" {
+			c.pattern, err = regexp.Compile(c.stringOption("pattern", moneyPattern.String()))
+			if err != nil {
+				return conf, errors.Wrapf(err, "pattern of lint rule %s is invalid", name)
+			}
+			conf.Rules[name] = c
+		}
+	}
+	return conf, nil
+}
+
+func (c LintRuleConfig) stringOption(key, def string) string {
+	s, ok := c.Options[key].(string)
+	if !ok {
+		return def
+	}
+	return s
+}
+
+func (c LintRuleConfig) stringsOption(key string, def []string) []string {
+	is, ok := c.Options[key].([]interface{})
+	if !ok {
+		return def
+	}
+	ss := []string{}
+	for _, i := range is {
+		if s, ok := i.(string); ok {
+			ss = append(ss, s)
+		}
+	}
+	return ss
+}
+
+func (t Table) findLintIgnore(m map[string]interface{}) ([]string, error) {
+	if m["lint_ignore"] == nil {
+		return nil, nil
+	}
+	is, ok := m["lint_ignore"].([]interface{})
+	if !ok {
+		return nil, errors.New("fail to convert type to []interface{}")
+	}
+
+	rules := []string{}
+	for _, i := range is {
+		s, ok := i.(string)
+		if !ok {
+			return nil, fmt.Errorf("fail to convert string type from %v", i)
+		}
+		if _, err := findLintRule(s); err != nil {
+			return nil, err
+		}
+		rules = append(rules, s)
+	}
+	return rules, nil
+}
+
+// isLintIgnored reports whether the problem is in a table which ignores the rule.
+func (s State) isLintIgnored(p Problem) bool {
+	for _, t := range s.Tables {
+		if !strings.HasPrefix(p.Pointer, tablePointer(t)+"/") {
+			continue
+		}
+		for _, r := range t.LintIgnore {
+			if r == p.Rule {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var (
+	snakeCase    = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	moneyPattern = regexp.MustCompile(`price|amount|cost|fee|money|balance`)
+)
+
+func lintSnakeCase(s State, _ LintRuleConfig) []Problem {
+	ps := []Problem{}
+	for _, i := range s.identifiers() {
+		if !snakeCase.MatchString(i.name) {
+			ps = append(ps, Problem{
+				Pointer: i.pointer,
+				Message: fmt.Sprintf("identifier %s is not snake_case", i.name),
+			})
+		}
+	}
+	return ps
+}
+
+// lintNamePrefix checks the names of the indexes, primary keys and foreign keys.
+// Unique keys are declared by unique of the column, and MySQL names them after
+// the column, so they have no name of their own to check.
+func lintNamePrefix(s State, c LintRuleConfig) []Problem {
+	ps := []Problem{}
+	prefix := func(pointer, kind, name, p string) {
+		if p != "" && !strings.HasPrefix(name, p) {
+			ps = append(ps, Problem{
+				Pointer: pointer,
+				Message: fmt.Sprintf("%s %s does not start with %s", kind, name, p),
+			})
+		}
+	}
+
+	idx, pk, fk := c.stringOption("index", "idx_"), c.stringOption("primary_key", ""), c.stringOption("foreign_key", "fk_")
+	for _, t := range s.Tables {
+		for _, k := range t.Index {
+			prefix(tablePointer(t)+"/table/index/"+k.Name, "index", k.Name, idx)
+		}
+		for _, k := range t.PrimaryKey {
+			prefix(tablePointer(t)+"/table/primary_key/"+k.Name, "primary key", k.Name, pk)
+		}
+	}
+	for _, k := range s.ForeignKey {
+		prefix(columnPointer(k.SourceTable, k.SourceColumn)+"/column/foreign_key/name", "foreign key", k.Name, fk)
+	}
+	return ps
+}
+
+func lintTimestamps(s State, c LintRuleConfig) []Problem {
+	ps := []Problem{}
+	columns := c.stringsOption("columns", []string{"created_at", "updated_at"})
+	autoUpdate := c.stringsOption("auto_update", []string{"updated_at"})
+	for _, t := range s.Tables {
+		names := map[string]Column{}
+		for _, col := range t.Column {
+			names[col.Name] = col
+		}
+		for _, n := range columns {
+			if _, ok := names[n]; !ok {
+				ps = append(ps, Problem{
+					Pointer: tablePointer(t) + "/properties",
+					Message: fmt.Sprintf("table %s has no %s column", t.Name, n),
+				})
+			}
+		}
+		for _, n := range autoUpdate {
+			if col, ok := names[n]; ok && !col.AutoUpdate {
+				ps = append(ps, Problem{
+					Pointer: columnPointer(t, col) + "/column/auto_update",
+					Message: fmt.Sprintf("column %s in table %s is not auto_update", n, t.Name),
+				})
+			}
+		}
+	}
+	return ps
+}
+
+func lintForeignKeyIndexed(s State, _ LintRuleConfig) []Problem {
+	ps := []Problem{}
+	for _, fk := range s.ForeignKey {
+		t, err := s.findTableWithID(fk.SourceTable.Id)
+		if err != nil {
+			t = fk.SourceTable
+		}
+		if !t.isKeyHead(fk.SourceColumn) {
+			ps = append(ps, Problem{
+				Pointer: columnPointer(fk.SourceTable, fk.SourceColumn) + "/column/foreign_key",
+				Message: fmt.Sprintf("foreign key column %s is not the first column of any index in table %s", fk.SourceColumn.Name, t.Name),
+			})
+		}
+	}
+	return ps
+}
+
+func lintFloatMoney(s State, c LintRuleConfig) []Problem {
+	ps := []Problem{}
+	pattern := c.pattern
+	if pattern == nil {
+		pattern = moneyPattern
+	}
+	for _, t := range s.Tables {
+		for _, col := range t.Column {
+			switch col.columnType().Base {
+			case "float", "double", "real":
+			default:
+				continue
+			}
+			if pattern.MatchString(col.Name) {
+				ps = append(ps, Problem{
+					Pointer: columnPointer(t, col) + "/column/type",
+					Message: fmt.Sprintf("money column %s in table %s is %s, use decimal", col.Name, t.Name, col.Type),
+				})
+			}
+		}
+	}
+	return ps
+}
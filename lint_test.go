@@ -0,0 +1,248 @@
+package migo_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/meta-closure/migo"
+)
+
+func TestValidate(t *testing.T) {
+	type Case struct {
+		input            migo.ValidateOption
+		expectedProblems []migo.Problem
+		hasError         bool
+		spec             string
+	}
+
+	cases := []Case{
+		{
+			input: migo.ValidateOption{
+				Migrate: migo.MigrateOption{
+					SchemaFile: "./test/lint_test.yml",
+					FormatType: "yaml",
+				},
+			},
+			expectedProblems: []migo.Problem{
+				{Pointer: "/definitions/order/properties/serial/column/auto_increment", Severity: migo.SeverityError, Rule: "auto-increment-not-key"},
+				{Pointer: "/definitions/order/properties/user_email/column/foreign_key/target_column", Severity: migo.SeverityError, Rule: "foreign-key-not-unique"},
+				{Pointer: "/definitions/order/properties/user_id/column/foreign_key", Severity: migo.SeverityError, Rule: "foreign-key-type-mismatch"},
+				{Pointer: "/definitions/order/table", Severity: migo.SeverityWarning, Rule: "no-primary-key"},
+				{Pointer: "/definitions/order/table/name", Severity: migo.SeverityWarning, Rule: "reserved-word"},
+			},
+			hasError: true,
+			spec:     "problems in schema",
+		},
+		{
+			input: migo.ValidateOption{
+				Migrate: migo.MigrateOption{
+					SchemaFile: "./test/lint_meta_schema_test.yml",
+					FormatType: "yaml",
+				},
+			},
+			expectedProblems: []migo.Problem{
+				{Pointer: "/definitions/user/properties/group_id/column/foreign_key", Severity: migo.SeverityError, Rule: "meta-schema"},
+				{Pointer: "/definitions/user/properties/group_id/column/foreign_key", Severity: migo.SeverityError, Rule: "schema"},
+				{Pointer: "/definitions/user/properties/group_id/column/foreign_key/update_cascade", Severity: migo.SeverityError, Rule: "meta-schema"},
+				{Pointer: "/definitions/user/properties/id/column/not_nul", Severity: migo.SeverityError, Rule: "meta-schema"},
+				{Pointer: "/definitions/user/table/primary_keys", Severity: migo.SeverityError, Rule: "meta-schema"},
+			},
+			hasError: true,
+			spec:     "unknown keys and wrong types",
+		},
+		{
+			input: migo.ValidateOption{
+				Migrate: migo.MigrateOption{
+					SchemaFile: "./test/lint_meta_schema_and_rules_test.yml",
+					FormatType: "yaml",
+				},
+			},
+			expectedProblems: []migo.Problem{
+				{Pointer: "/definitions/user/properties/id/column/not_nul", Severity: migo.SeverityError, Rule: "meta-schema"},
+				{Pointer: "/definitions/user/table", Severity: migo.SeverityWarning, Rule: "no-primary-key"},
+			},
+			hasError: true,
+			spec:     "unknown key and problems of rules together",
+		},
+		{
+			input: migo.ValidateOption{
+				Migrate: migo.MigrateOption{
+					SchemaFile: "./test/lint_convention_test.yml",
+					FormatType: "yaml",
+				},
+				RulesFile: "./test/lint_rules.yml",
+			},
+			expectedProblems: []migo.Problem{
+				{Pointer: "/definitions/item/properties/price/column/type", Severity: migo.SeverityWarning, Rule: "no-float-money"},
+				{Pointer: "/definitions/item/table/index/price", Severity: migo.SeverityWarning, Rule: "name-prefix"},
+				{Pointer: "/definitions/item/table/name", Severity: migo.SeverityError, Rule: "snake-case"},
+			},
+			hasError: true,
+			spec:     "configured rules with suppression",
+		},
+		{
+			input: migo.ValidateOption{
+				Migrate: migo.MigrateOption{
+					SchemaFile: "./test/parse_test_partition.yml",
+					FormatType: "yaml",
+				},
+			},
+			expectedProblems: []migo.Problem{},
+			hasError:         false,
+			spec:             "correct schema",
+		},
+		{
+			input: migo.ValidateOption{
+				Migrate: migo.MigrateOption{
+					SchemaFile: "./test/parse_test_fail_by_pk.yml",
+					FormatType: "yaml",
+				},
+			},
+			expectedProblems: []migo.Problem{
+				{Pointer: "/definitions/test_table/table/primary_key/test_pk", Severity: migo.SeverityError, Rule: "schema"},
+			},
+			hasError: true,
+			spec:     "unreadable key",
+		},
+		{
+			input: migo.ValidateOption{
+				Migrate: migo.MigrateOption{
+					SchemaFile: "./test/lint_schema_test.yml",
+					FormatType: "yaml",
+				},
+			},
+			expectedProblems: []migo.Problem{
+				{Pointer: "/definitions/event/table/partition", Severity: migo.SeverityError, Rule: "schema"},
+				{Pointer: "/definitions/order/properties/user_id/column/foreign_key", Severity: migo.SeverityError, Rule: "schema"},
+				{Pointer: "/definitions/order/table/primary_key/order_pk", Severity: migo.SeverityError, Rule: "schema"},
+			},
+			hasError: true,
+			spec:     "every unreadable part of schema",
+		},
+	}
+
+	for _, c := range cases {
+		ps, err := migo.Validate(c.input)
+		if err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+			continue
+		}
+
+		if ps.HasError() != c.hasError {
+			t.Errorf("in %s, expected to have error is %t, but actual %t", c.spec, c.hasError, ps.HasError())
+		}
+		if len(ps) != len(c.expectedProblems) {
+			t.Errorf("in %s, expected problems are %+v, but actual %+v", c.spec, c.expectedProblems, ps)
+			continue
+		}
+		for i, p := range ps {
+			e := c.expectedProblems[i]
+			if p.Pointer != e.Pointer || p.Severity != e.Severity || p.Rule != e.Rule {
+				t.Errorf("in %s, expected problem is %+v, but actual %+v", c.spec, e, p)
+			}
+		}
+	}
+}
+
+func TestNewLintConfig(t *testing.T) {
+	type Case struct {
+		input     string
+		isSuccess bool
+		spec      string
+	}
+
+	cases := []Case{
+		{
+			input:     "./test/lint_rules.yml",
+			isSuccess: true,
+			spec:      "correct rules file",
+		},
+		{
+			input:     "./test/lint_rules_fail_by_pattern.yml",
+			isSuccess: false,
+			spec:      "invalid pattern of no-float-money",
+		},
+	}
+
+	for _, c := range cases {
+		_, err := migo.NewLintConfig(c.input)
+		if !c.isSuccess && err == nil {
+			t.Errorf("in %s, error is expected but null", c.spec)
+			continue
+		}
+		if c.isSuccess && err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+		}
+	}
+}
+
+func TestProblemsWrite(t *testing.T) {
+	type Case struct {
+		input     migo.MigrateOption
+		isSuccess bool
+		spec      string
+	}
+
+	cases := []Case{
+		{
+			input:     migo.MigrateOption{SchemaFile: "./test/lint_meta_schema_test.yml", FormatType: "yaml"},
+			isSuccess: true,
+			spec:      "rules of meta-schema and schema problems",
+		},
+		{
+			input:     migo.MigrateOption{SchemaFile: "./test/lint_test.yml", FormatType: "yaml"},
+			isSuccess: true,
+			spec:      "rules of lint problems",
+		},
+	}
+
+	for _, c := range cases {
+		ps, err := migo.Validate(migo.ValidateOption{Migrate: c.input})
+		if err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+			continue
+		}
+
+		b := &bytes.Buffer{}
+		err = ps.Write(b, migo.FormatSARIF, c.input.SchemaFile)
+		if !c.isSuccess && err == nil {
+			t.Errorf("in %s, error is expected but null", c.spec)
+			continue
+		}
+		if c.isSuccess && err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		var sarif struct {
+			Runs []struct {
+				Tool struct {
+					Driver struct {
+						Rules []struct {
+							Id string `json:"id"`
+						} `json:"rules"`
+					} `json:"driver"`
+				} `json:"tool"`
+				Results []struct {
+					RuleId string `json:"ruleId"`
+				} `json:"results"`
+			} `json:"runs"`
+		}
+		if err := json.Unmarshal(b.Bytes(), &sarif); err != nil {
+			t.Fatal(err)
+		}
+		rules := map[string]bool{}
+		for _, r := range sarif.Runs[0].Tool.Driver.Rules {
+			rules[r.Id] = true
+		}
+		for _, r := range sarif.Runs[0].Results {
+			if !rules[r.RuleId] {
+				t.Errorf("in %s, expected rule %s is in tool.driver.rules, but not", c.spec, r.RuleId)
+			}
+		}
+	}
+}
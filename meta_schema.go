@@ -0,0 +1,300 @@
+package migo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	hschema "github.com/lestrrat/go-jshschema"
+	schema "github.com/lestrrat/go-jsschema"
+	"github.com/pkg/errors"
+)
+
+// MetaSchema is the JSON Schema of the table, column and foreign_key blocks,
+// which can be given to editors to complete and check the schema file.
+const MetaSchema = `{
+  "$schema": "http://json-schema.org/draft-04/schema#",
+  "title": "migo extension keywords",
+  "definitions": {
+    "key": {
+      "type": "object",
+      "additionalProperties": {
+        "type": "array",
+        "items": {"type": "string"}
+      }
+    },
+    "table": {
+      "type": "object",
+      "required": ["name"],
+      "additionalProperties": false,
+      "properties": {
+        "name": {"type": "string"},
+        "renamed_from": {"type": "string"},
+        "primary_key": {"$ref": "#/definitions/key"},
+        "index": {"$ref": "#/definitions/key"},
+        "trigger": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "object",
+            "required": ["timing", "event", "body"],
+            "additionalProperties": false,
+            "properties": {
+              "timing": {"type": "string", "enum": ["before", "after", "BEFORE", "AFTER"]},
+              "event": {"type": "string", "enum": ["insert", "update", "delete", "INSERT", "UPDATE", "DELETE"]},
+              "body": {"type": "string"}
+            }
+          }
+        },
+        "data": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "object",
+            "required": ["sql"],
+            "additionalProperties": false,
+            "properties": {
+              "timing": {"type": "string", "enum": ["before", "after", "BEFORE", "AFTER"]},
+              "column": {"type": "string"},
+              "sql": {"type": "string"},
+              "rollback": {"type": "string"},
+              "chunk_size": {"type": "integer"}
+            }
+          }
+        },
+        "partition": {
+          "type": "object",
+          "required": ["type"],
+          "additionalProperties": false,
+          "properties": {
+            "type": {"type": "string", "enum": ["range", "list", "hash", "key", "RANGE", "LIST", "HASH", "KEY"]},
+            "columns": {"type": "array", "items": {"type": "string"}},
+            "expression": {"type": "string"},
+            "count": {"type": "integer"},
+            "partitions": {
+              "type": "array",
+              "items": {
+                "type": "object",
+                "required": ["name", "values"],
+                "additionalProperties": false,
+                "properties": {
+                  "name": {"type": "string"},
+                  "values": {"type": "string"}
+                }
+              }
+            },
+            "policy": {
+              "type": "object",
+              "required": ["interval"],
+              "additionalProperties": false,
+              "properties": {
+                "interval": {"type": "string", "enum": ["day", "month", "year"]},
+                "ahead": {"type": "integer"},
+                "retention": {"type": "integer"}
+              }
+            }
+          }
+        },
+        "lint_ignore": {"type": "array", "items": {"type": "string"}}
+      }
+    },
+    "column": {
+      "type": "object",
+      "required": ["name"],
+      "additionalProperties": false,
+      "properties": {
+        "name": {"type": "string"},
+        "type": {"type": "string"},
+        "unique": {"type": "boolean"},
+        "auto_increment": {"type": "boolean"},
+        "auto_update": {"type": "boolean"},
+        "not_null": {"type": "boolean"},
+        "default": {},
+        "charset": {"type": "string"},
+        "collation": {"type": "string"},
+        "comment": {"type": "string"},
+        "expression": {"type": "string"},
+        "storage": {"type": "string", "enum": ["virtual", "stored", "VIRTUAL", "STORED"]},
+        "renamed_from": {"type": "string"},
+        "foreign_key": {"$ref": "#/definitions/foreign_key"}
+      }
+    },
+    "foreign_key": {
+      "type": "object",
+      "required": ["name", "target_table", "target_column"],
+      "additionalProperties": false,
+      "properties": {
+        "name": {"type": "string"},
+        "target_table": {"type": "string"},
+        "target_column": {"type": "string"},
+        "update_cascade": {"type": "boolean"},
+        "delete_cascade": {"type": "boolean"}
+      }
+    }
+  }
+}`
+
+var metaSchema map[string]interface{}
+
+func init() {
+	if err := json.Unmarshal([]byte(MetaSchema), &metaSchema); err != nil {
+		panic(err)
+	}
+}
+
+func escapePointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}
+
+func metaDefinition(name string) map[string]interface{} {
+	ds, _ := metaSchema["definitions"].(map[string]interface{})
+	d, _ := ds[name].(map[string]interface{})
+	return d
+}
+
+func typeOf(v interface{}) string {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if t == math.Trunc(t) {
+			return "integer"
+		}
+		return "number"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// validateMeta checks v against the subset of JSON Schema used in MetaSchema,
+// reporting the problems with the JSON pointer to v.
+func validateMeta(s map[string]interface{}, v interface{}, pointer string) []Problem {
+	if ref, ok := s["$ref"].(string); ok {
+		return validateMeta(metaDefinition(strings.TrimPrefix(ref, "#/definitions/")), v, pointer)
+	}
+
+	problem := func(format string, a ...interface{}) []Problem {
+		return []Problem{{Pointer: pointer, Message: fmt.Sprintf(format, a...)}}
+	}
+	if t, ok := s["type"].(string); ok {
+		actual := typeOf(v)
+		if actual != t && !(t == "number" && actual == "integer") {
+			return problem("expected %s but got %s", t, actual)
+		}
+	}
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			found = found || e == v
+		}
+		if !found {
+			return problem("%v is not one of %v", v, enum)
+		}
+	}
+
+	ps := []Problem{}
+	switch t := v.(type) {
+	case []interface{}:
+		items, _ := s["items"].(map[string]interface{})
+		for i, e := range t {
+			ps = append(ps, validateMeta(items, e, fmt.Sprintf("%s/%d", pointer, i))...)
+		}
+	case map[string]interface{}:
+		required, _ := s["required"].([]interface{})
+		for _, r := range required {
+			if _, ok := t[r.(string)]; !ok {
+				ps = append(ps, problem("required key `%s` is not found", r)...)
+			}
+		}
+
+		keys := []string{}
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		properties, _ := s["properties"].(map[string]interface{})
+		for _, k := range keys {
+			p := pointer + "/" + escapePointer(k)
+			if ps2, ok := properties[k].(map[string]interface{}); ok {
+				ps = append(ps, validateMeta(ps2, t[k], p)...)
+				continue
+			}
+			switch a := s["additionalProperties"].(type) {
+			case bool:
+				if !a {
+					ps = append(ps, Problem{Pointer: p, Message: fmt.Sprintf("unknown key `%s`", k)})
+				}
+			case map[string]interface{}:
+				ps = append(ps, validateMeta(a, t[k], p)...)
+			}
+		}
+	}
+	return ps
+}
+
+func findSchemaMetaProblems(s *schema.Schema, pointer string) []Problem {
+	ps := []Problem{}
+	if s.Extras["table"] != nil {
+		ps = append(ps, validateMeta(metaDefinition("table"), s.Extras["table"], pointer+"/table")...)
+	}
+
+	keys := []string{}
+	for k := range s.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		c := s.Properties[k].Extras["column"]
+		if c == nil {
+			continue
+		}
+		p := fmt.Sprintf("%s/properties/%s/column", pointer, escapePointer(k))
+		ps = append(ps, validateMeta(metaDefinition("column"), c, p)...)
+	}
+	return ps
+}
+
+// FindMetaSchemaProblems validates every table, column and foreign_key block
+// in the schema against MetaSchema.
+func FindMetaSchemaProblems(root *hschema.HyperSchema) Problems {
+	ps := Problems{}
+	for _, section := range []struct {
+		name    string
+		schemas map[string]*schema.Schema
+	}{
+		{"definitions", root.Definitions},
+		{"properties", root.Properties},
+	} {
+		for k, v := range section.schemas {
+			for _, p := range findSchemaMetaProblems(v, fmt.Sprintf("/%s/%s", section.name, escapePointer(k))) {
+				p.Rule, p.Severity = "meta-schema", SeverityError
+				ps = append(ps, p)
+			}
+		}
+	}
+	sort.Sort(ps)
+	return ps
+}
+
+func (p Problems) Error() string {
+	ms := []string{}
+	for _, problem := range p {
+		ms = append(ms, fmt.Sprintf("%s: %s", problem.Pointer, problem.Message))
+	}
+	return strings.Join(ms, ", ")
+}
+
+func validateMetaSchema(root *hschema.HyperSchema) error {
+	if ps := FindMetaSchemaProblems(root); len(ps) > 0 {
+		return errors.Wrap(ps, "invalid extension keywords")
+	}
+	return nil
+}
@@ -16,8 +16,27 @@ func (op MigrateOption) isJSONFormat() bool {
 	return op.FormatType == "json"
 }
 
+// ReadSchema reads the schema file merged with the overlay of the environment if exists.
 func ReadSchema(op MigrateOption) (*hschema.HyperSchema, error) {
+	if op.hasOverlay() {
+		return readOverlaidSchema(op)
+	}
+	return ReadBaseSchema(op)
+}
+
+func ReadBaseSchema(op MigrateOption) (*hschema.HyperSchema, error) {
 	h := hschema.New()
+	if op.isMultiFile() {
+		m, err := readMultiFileSchemaMap(op)
+		if err != nil {
+			return h, err
+		}
+		if err := h.Extract(m); err != nil {
+			return h, errors.Wrap(err, "merging schema files")
+		}
+		return h, nil
+	}
+
 	if op.isYAMLFormat() {
 		if err := readYAMLFormatSchema(h, op.SchemaFile); err != nil {
 			return h, err
@@ -41,6 +60,14 @@ func Plan(op MigrateOption) error {
 		return err
 	}
 
+	hooks, err := NewHooks(op.HooksFile)
+	if err != nil {
+		return errors.Wrap(err, "reading hooks")
+	}
+	if err := db.runHooks(nil, hookBeforePlan, hooks.BeforePlan, NewHookPayload(op.Environment, Operations{})); err != nil {
+		return err
+	}
+
 	old, err := NewStateFromYAML(op.StateFile)
 	if err != nil {
 		return errors.Wrap(err, "State YAML file parse error")
@@ -51,17 +78,19 @@ func Plan(op MigrateOption) error {
 		return errors.Wrap(err, "parsing hyper-schema from yaml")
 	}
 
-	new, err := NewStateFromSchema(h)
+	new, err := NewStateFromSchema(h, op.Schema)
 	if err != nil {
 		return errors.Wrap(err, "parsing state from hyper-schema")
 	}
 	new.DB = db
 
-	ops, err := NewOperations(old, new)
+	ops, err := NewOverlaidOperations(old, &new, op)
 	if err != nil {
 		return errors.Wrap(err, "creating requests")
 	}
 	Announce(ops, db)
+	AnnounceInferredColumns(new)
+	AnnouncePendingContracts(new)
 	return nil
 }
 
@@ -71,6 +100,21 @@ func Run(op MigrateOption) error {
 		return err
 	}
 
+	hooks, err := NewHooks(op.HooksFile)
+	if err != nil {
+		return errors.Wrap(err, "reading hooks")
+	}
+
+	mysql, err := db.open()
+	if err != nil {
+		return err
+	}
+	defer mysql.Close()
+
+	if err := db.runHooks(mysql, hookBeforePlan, hooks.BeforePlan, NewHookPayload(op.Environment, Operations{})); err != nil {
+		return err
+	}
+
 	old, err := NewStateFromYAML(op.StateFile)
 	if err != nil {
 		return errors.Wrap(err, "State YAML file parse error")
@@ -81,48 +125,99 @@ func Run(op MigrateOption) error {
 		return errors.Wrap(err, "parsing hyper-schema from yaml")
 	}
 
-	new, err := NewStateFromSchema(h)
+	new, err := NewStateFromSchema(h, op.Schema)
 	if err != nil {
 		return errors.Wrap(err, "parsing state from hyper-schema")
 	}
 	new.DB = db
 
-	ops, err := NewOperations(old, new)
+	ops, err := NewOverlaidOperations(old, &new, op)
 	if err != nil {
 		return errors.Wrap(err, "creating requests")
 	}
 
 	Announce(ops, db)
-	if err := db.migrate(ops); err != nil {
+	AnnounceInferredColumns(new)
+
+	p := NewHookPayload(op.Environment, ops)
+	if err := db.runHooks(mysql, hookBeforeRun, hooks.BeforeRun, p); err != nil {
+		return err
+	}
+	afterEach := func(o Operation) error {
+		p := p
+		ho := NewHookOperation(o)
+		p.Operation = &ho
+		return db.runHooks(mysql, hookAfterEachOperation, hooks.AfterEachOperation, p)
+	}
+	if err := db.migrate(mysql, ops, afterEach); err != nil {
+		p.Error = err.Error()
+		if herr := db.runHooks(mysql, hookAfterFailure, hooks.AfterFailure, p); herr != nil {
+			return errors.Wrapf(herr, "migrate error with `%s` and hook failed", err)
+		}
 		return err
 	}
 
+	new.clearRenamedFrom()
 	if err = new.save(op.StateFile); err != nil {
 		return errors.Wrap(err, "saving state file")
 	}
-	return nil
+	return db.runHooks(mysql, hookAfterSuccess, hooks.AfterSuccess, p)
 }
 
-func (db DB) migrate(ops Operations) error {
-	if err := db.exec(&ops); err != nil {
-		if rerr := db.rollback(ops); rerr != nil {
-			return errors.Wrapf(rerr, "migrate error with `%s` and recovery failed", err)
+// NewPhasedOperations returns the operations from current to new state following
+// the expand/contract options, and records the contracts left pending in new state.
+func NewPhasedOperations(current State, new *State, op MigrateOption) (Operations, error) {
+	new.inheritMaintainedPartitions(current)
+	ops, err := NewOperations(current, *new)
+	if err != nil {
+		return ops, err
+	}
+	if op.Expand {
+		ops = ops.Expand()
+	}
+
+	pending := new.findPendingContracts(current.Contract)
+	if op.Contract {
+		cops, err := NewContractOperations(pending, *new)
+		if err != nil {
+			return ops, errors.Wrap(err, "creating contract requests")
 		}
-		return errors.Wrap(err, "migration failed")
+		ops.Operation = append(ops.Operation, cops.Operation...)
+		pending = Contracts{}
 	}
-	return nil
+	new.Contract = append(pending, ops.Contract...)
+	new.AppliedDataSteps = append(append([]string{}, current.AppliedDataSteps...), ops.appliedDataSteps()...)
+	return ops, nil
 }
 
-func (db DB) rollback(ops Operations) error {
+// open returns the connection of a migration, kept to a single session so that
+// the SQL hooks and the operations share its variables.
+func (db DB) open() (*sql.DB, error) {
 	mysql, err := sql.Open("mysql", db.FormatDSN())
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer mysql.Close()
+	mysql.SetMaxOpenConns(1)
+	return mysql, nil
+}
 
+func (db DB) migrate(mysql *sql.DB, ops Operations, afterEach func(Operation) error) error {
+	if err := db.exec(mysql, &ops, afterEach); err != nil {
+		if rerr := db.rollback(mysql, ops); rerr != nil {
+			return errors.Wrapf(rerr, "migrate error with `%s` and recovery failed", err)
+		}
+		return errors.Wrap(err, "migration failed")
+	}
+	return nil
+}
+
+func (db DB) rollback(mysql *sql.DB, ops Operations) error {
 	for i := 1; i < ops.execCount+1; i++ {
-		if _, err = mysql.Exec(ops.Operation[ops.execCount-i].RollBack()); err != nil {
-			fmt.Println(">>>>>>>> RECOVERY FAILED\n")
+		if ops.Operation[ops.execCount-i].RollBack() == "" {
+			continue
+		}
+		if _, err := mysql.Exec(ops.Operation[ops.execCount-i].RollBack()); err != nil {
+			fmt.Print(">>>>>>>> RECOVERY FAILED\n\n")
 			return errors.Wrapf(err, "RollBack: %s", ops.Operation[ops.execCount-i].RollBack())
 		}
 	}
@@ -131,29 +226,79 @@ func (db DB) rollback(ops Operations) error {
 	return nil
 }
 
-func (db DB) exec(ops *Operations) error {
-	mysql, err := sql.Open("mysql", db.FormatDSN())
-	if err != nil {
-		return err
-	}
-	defer mysql.Close()
-
+// exec runs the operations, calling afterEach after each of them succeeds.
+func (db DB) exec(mysql *sql.DB, ops *Operations, afterEach func(Operation) error) error {
 	for i, op := range ops.Operation {
-		if _, err := mysql.Exec(op.Query()); err != nil {
-			fmt.Println(">>>>>>>> MIGRATION FAILED\n")
+		if c, ok := op.(chunkedOperation); ok && c.isChunked() {
+			if err := c.execChunks(mysql); err != nil {
+				fmt.Print(">>>>>>>> MIGRATION FAILED\n\n")
+				ops.execCount = i
+				return err
+			}
+		} else if _, err := mysql.Exec(op.Query()); err != nil {
+			fmt.Print(">>>>>>>> MIGRATION FAILED\n\n")
 			ops.execCount = i
 			return errors.Wrapf(err, "Query: %s", op.Query())
 		}
+		if err := afterEach(op); err != nil {
+			fmt.Print(">>>>>>>> MIGRATION FAILED\n\n")
+			ops.execCount = i + 1
+			return err
+		}
 	}
 
-	fmt.Println(">>>>>>>> MIGRATION SUCCEED\n")
+	fmt.Print(">>>>>>>> MIGRATION SUCCEED\n\n")
 	return nil
 }
 
 func Announce(ops Operations, db DB) {
-	fmt.Println("\n---------- DATABASE MIGRATION IS .......\n")
+	fmt.Print("\n---------- DATABASE MIGRATION IS .......\n\n")
 
 	fmt.Printf("DATABASE CONFIGURE: %s \n\n", db.FormatDSN())
+	for _, op := range ops.Operation {
+		if o, ok := ops.overlaid[op.Query()]; ok {
+			fmt.Printf("%s (FROM %s)\n", op.String(), o)
+			continue
+		}
+		fmt.Println(op.String())
+	}
+	for _, w := range ops.Warning {
+		fmt.Printf("WARNING: %s\n", w)
+	}
+}
+
+func AnnounceInferredColumns(s State) {
+	cs := []string{}
+	for _, t := range s.Tables {
+		for _, c := range t.findInferredColumns() {
+			if c.NotNull {
+				cs = append(cs, fmt.Sprintf("[%s] IN [%s]: %s NOT NULL", c.Name, t.Name, c.Type))
+				continue
+			}
+			cs = append(cs, fmt.Sprintf("[%s] IN [%s]: %s", c.Name, t.Name, c.Type))
+		}
+	}
+	if len(cs) == 0 {
+		return
+	}
+
+	fmt.Printf("\n---------- COLUMN TYPES INFERRED FROM JSON SCHEMA\n\n")
+	for _, c := range cs {
+		fmt.Println(c)
+	}
+}
+
+func AnnouncePendingContracts(s State) {
+	if len(s.Contract) == 0 {
+		return
+	}
+
+	fmt.Printf("\n---------- PENDING CONTRACT STEPS, APPLIED WITH --contract\n\n")
+	ops, err := NewContractOperations(s.Contract, s)
+	if err != nil {
+		fmt.Printf("ERROR: %s\n", err)
+		return
+	}
 	for _, op := range ops.Operation {
 		fmt.Println(op.String())
 	}
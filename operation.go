@@ -2,13 +2,19 @@ package migo
 
 import (
 	"fmt"
-	"regexp"
 	"strings"
 )
 
 type Operations struct {
 	execCount int
 	Operation []Operation
+	Warning   []string
+	Contract  Contracts
+	overlaid  map[string]string
+}
+
+func (ops *Operations) warn(format string, a ...interface{}) {
+	ops.Warning = append(ops.Warning, fmt.Sprintf(format, a...))
 }
 
 type Operation interface {
@@ -36,7 +42,14 @@ func (op CreateTable) Query() string {
 	for _, k := range op.Table.Index {
 		cols = append(cols, k.queryAsIndex())
 	}
-	return fmt.Sprintf("CREATE TABLE %s (%s)ENGINE=innoDB", op.Table.Name, strings.Join(cols, ","))
+	for _, c := range op.Table.Check {
+		cols = append(cols, c.query())
+	}
+	q := fmt.Sprintf("CREATE TABLE %s (%s)ENGINE=innoDB", op.Table.Name, strings.Join(cols, ","))
+	if op.Table.Partition.isPartitioned() {
+		q = fmt.Sprintf("%s %s", q, op.Table.Partition.query())
+	}
+	return q
 }
 
 func (op CreateTable) String() string {
@@ -47,14 +60,6 @@ func (op CreateTable) RollBack() string {
 	return NewDropTable(op.Table).Query()
 }
 
-func isDatetime(s string) bool {
-	return len(s) > 8 && s[:8] == "datetime"
-}
-
-func digit(s string) string {
-	return regexp.MustCompile(`\(.+\)`).FindString(s)
-}
-
 type DropTable struct {
 	Table Table
 }
@@ -224,9 +229,21 @@ func NewUpdateColumn(t Table, old, new Column) Operation {
 	}
 }
 func (op UpdateColumn) String() string {
+	if op.rebuildsTable() {
+		return fmt.Sprintf("CHANGE COLUMN [%s] IN [%s] (REBUILDS TABLE)", op.CurrentColumn.Name, op.Table.Name)
+	}
 	return fmt.Sprintf("CHANGE COLUMN [%s] IN [%s]", op.CurrentColumn.Name, op.Table.Name)
 }
 
+// rebuildsTable reports whether the change rewrites every row, which is the
+// case when the expression of a stored generated column is changed.
+func (op UpdateColumn) rebuildsTable() bool {
+	if !op.NewColumn.isStored() {
+		return false
+	}
+	return !op.CurrentColumn.isStored() || op.CurrentColumn.Expression != op.NewColumn.Expression
+}
+
 func (op UpdateColumn) Query() string {
 	return fmt.Sprintf("ALTER TABLE %s CHANGE COLUMN %s %s",
 		op.Table.Name,
@@ -323,3 +340,337 @@ func (op AddIndex) Query() string {
 func (op AddIndex) RollBack() string {
 	return NewDropIndex(op.Table, op.Index).Query()
 }
+
+type AddCheck struct {
+	Table Table
+	Check Check
+}
+
+func NewAddCheck(t Table, c Check) AddCheck {
+	return AddCheck{
+		Table: t,
+		Check: c,
+	}
+}
+func (op AddCheck) String() string {
+	return fmt.Sprintf("ADD CHECK %s IN %s", op.Check.Name, op.Table.Name)
+}
+func (op AddCheck) Query() string {
+	return fmt.Sprintf("ALTER TABLE %s ADD %s", op.Table.Name, op.Check.query())
+}
+func (op AddCheck) RollBack() string {
+	return NewDropCheck(op.Table, op.Check).Query()
+}
+
+type DropCheck struct {
+	Table Table
+	Check Check
+}
+
+func NewDropCheck(t Table, c Check) DropCheck {
+	return DropCheck{
+		Table: t,
+		Check: c,
+	}
+}
+func (op DropCheck) String() string {
+	return fmt.Sprintf("DROP CHECK %s IN %s", op.Check.Name, op.Table.Name)
+}
+func (op DropCheck) Query() string {
+	return fmt.Sprintf("ALTER TABLE %s DROP CHECK %s", op.Table.Name, op.Check.Name)
+}
+func (op DropCheck) RollBack() string {
+	return NewAddCheck(op.Table, op.Check).Query()
+}
+
+type CreateView struct {
+	View View
+}
+
+func NewCreateView(v View) CreateView {
+	return CreateView{View: v}
+}
+func (op CreateView) String() string {
+	return fmt.Sprintf("ADD VIEW: [%s]", op.View.Name)
+}
+func (op CreateView) Query() string {
+	return fmt.Sprintf("CREATE %s", op.View.query())
+}
+func (op CreateView) RollBack() string {
+	return NewDropView(op.View).Query()
+}
+
+type DropView struct {
+	View View
+}
+
+func NewDropView(v View) DropView {
+	return DropView{View: v}
+}
+func (op DropView) String() string {
+	return fmt.Sprintf("DROP VIEW: [%s]", op.View.Name)
+}
+func (op DropView) Query() string {
+	return fmt.Sprintf("DROP VIEW %s", op.View.Name)
+}
+func (op DropView) RollBack() string {
+	return NewCreateView(op.View).Query()
+}
+
+type AlterView struct {
+	CurrentView View
+	NewView     View
+}
+
+func NewAlterView(old, new View) AlterView {
+	return AlterView{
+		CurrentView: old,
+		NewView:     new,
+	}
+}
+func (op AlterView) String() string {
+	return fmt.Sprintf("ALTER VIEW: [%s]", op.NewView.Name)
+}
+func (op AlterView) Query() string {
+	return fmt.Sprintf("ALTER %s", op.NewView.query())
+}
+func (op AlterView) RollBack() string {
+	return NewAlterView(op.NewView, op.CurrentView).Query()
+}
+
+type CreateTrigger struct {
+	Table   Table
+	Trigger Trigger
+}
+
+func NewCreateTrigger(t Table, tr Trigger) CreateTrigger {
+	return CreateTrigger{
+		Table:   t,
+		Trigger: tr,
+	}
+}
+func (op CreateTrigger) String() string {
+	return fmt.Sprintf("ADD TRIGGER %s IN %s", op.Trigger.Name, op.Table.Name)
+}
+func (op CreateTrigger) Query() string {
+	return op.Trigger.query(op.Table)
+}
+func (op CreateTrigger) RollBack() string {
+	return NewDropTrigger(op.Table, op.Trigger).Query()
+}
+
+type DropTrigger struct {
+	Table   Table
+	Trigger Trigger
+}
+
+func NewDropTrigger(t Table, tr Trigger) DropTrigger {
+	return DropTrigger{
+		Table:   t,
+		Trigger: tr,
+	}
+}
+func (op DropTrigger) String() string {
+	return fmt.Sprintf("DROP TRIGGER %s IN %s", op.Trigger.Name, op.Table.Name)
+}
+func (op DropTrigger) Query() string {
+	return fmt.Sprintf("DROP TRIGGER %s", op.Trigger.Name)
+}
+func (op DropTrigger) RollBack() string {
+	return NewCreateTrigger(op.Table, op.Trigger).Query()
+}
+
+type CreateRoutine struct {
+	Routine Routine
+}
+
+func NewCreateRoutine(r Routine) CreateRoutine {
+	return CreateRoutine{Routine: r}
+}
+func (op CreateRoutine) String() string {
+	return fmt.Sprintf("ADD %s: [%s]", strings.ToUpper(op.Routine.Type), op.Routine.Name)
+}
+func (op CreateRoutine) Query() string {
+	return fmt.Sprintf("CREATE %s", op.Routine.query())
+}
+func (op CreateRoutine) RollBack() string {
+	return NewDropRoutine(op.Routine).Query()
+}
+
+type DropRoutine struct {
+	Routine Routine
+}
+
+func NewDropRoutine(r Routine) DropRoutine {
+	return DropRoutine{Routine: r}
+}
+func (op DropRoutine) String() string {
+	return fmt.Sprintf("DROP %s: [%s]", strings.ToUpper(op.Routine.Type), op.Routine.Name)
+}
+func (op DropRoutine) Query() string {
+	return fmt.Sprintf("DROP %s %s", strings.ToUpper(op.Routine.Type), op.Routine.Name)
+}
+func (op DropRoutine) RollBack() string {
+	return NewCreateRoutine(op.Routine).Query()
+}
+
+type CreateEvent struct {
+	Event Event
+}
+
+func NewCreateEvent(e Event) CreateEvent {
+	return CreateEvent{Event: e}
+}
+func (op CreateEvent) String() string {
+	return fmt.Sprintf("ADD EVENT: [%s]", op.Event.Name)
+}
+func (op CreateEvent) Query() string {
+	return fmt.Sprintf("CREATE %s", op.Event.query())
+}
+func (op CreateEvent) RollBack() string {
+	return NewDropEvent(op.Event).Query()
+}
+
+type DropEvent struct {
+	Event Event
+}
+
+func NewDropEvent(e Event) DropEvent {
+	return DropEvent{Event: e}
+}
+func (op DropEvent) String() string {
+	return fmt.Sprintf("DROP EVENT: [%s]", op.Event.Name)
+}
+func (op DropEvent) Query() string {
+	return fmt.Sprintf("DROP EVENT %s", op.Event.Name)
+}
+func (op DropEvent) RollBack() string {
+	return NewCreateEvent(op.Event).Query()
+}
+
+type PartitionTable struct {
+	Table            Table
+	CurrentPartition Partition
+	NewPartition     Partition
+}
+
+func NewPartitionTable(t Table, current, new Partition) PartitionTable {
+	return PartitionTable{
+		Table:            t,
+		CurrentPartition: current,
+		NewPartition:     new,
+	}
+}
+func (op PartitionTable) String() string {
+	if !op.NewPartition.isPartitioned() {
+		return fmt.Sprintf("REMOVE PARTITIONING IN %s", op.Table.Name)
+	}
+	return fmt.Sprintf("PARTITION %s BY %s", op.Table.Name, strings.ToUpper(op.NewPartition.Type))
+}
+func (op PartitionTable) Query() string {
+	if !op.NewPartition.isPartitioned() {
+		return fmt.Sprintf("ALTER TABLE %s REMOVE PARTITIONING", op.Table.Name)
+	}
+	return fmt.Sprintf("ALTER TABLE %s %s", op.Table.Name, op.NewPartition.query())
+}
+func (op PartitionTable) RollBack() string {
+	return NewPartitionTable(op.Table, op.NewPartition, op.CurrentPartition).Query()
+}
+
+type AddPartition struct {
+	Table      Table
+	Partition  Partition
+	Partitions PartitionDefinitions
+}
+
+func NewAddPartition(t Table, p Partition, ds PartitionDefinitions) AddPartition {
+	return AddPartition{
+		Table:      t,
+		Partition:  p,
+		Partitions: ds,
+	}
+}
+func (op AddPartition) String() string {
+	return fmt.Sprintf("ADD PARTITION %s IN %s", op.Partitions.names(), op.Table.Name)
+}
+func (op AddPartition) Query() string {
+	return fmt.Sprintf("ALTER TABLE %s ADD PARTITION %s", op.Table.Name, op.Partitions.query(op.Partition))
+}
+func (op AddPartition) RollBack() string {
+	return NewDropPartition(op.Table, op.Partition, op.Partitions).Query()
+}
+
+type DropPartition struct {
+	Table      Table
+	Partition  Partition
+	Partitions PartitionDefinitions
+}
+
+func NewDropPartition(t Table, p Partition, ds PartitionDefinitions) DropPartition {
+	return DropPartition{
+		Table:      t,
+		Partition:  p,
+		Partitions: ds,
+	}
+}
+func (op DropPartition) String() string {
+	return fmt.Sprintf("DROP PARTITION %s IN %s (DELETES ROWS)", op.Partitions.names(), op.Table.Name)
+}
+func (op DropPartition) Query() string {
+	return fmt.Sprintf("ALTER TABLE %s DROP PARTITION %s", op.Table.Name, op.Partitions.names())
+}
+func (op DropPartition) RollBack() string {
+	return NewAddPartition(op.Table, op.Partition, op.Partitions).Query()
+}
+
+type ReorganizePartition struct {
+	Table             Table
+	Partition         Partition
+	CurrentPartitions PartitionDefinitions
+	NewPartitions     PartitionDefinitions
+}
+
+func NewReorganizePartition(t Table, p Partition, current, new PartitionDefinitions) ReorganizePartition {
+	return ReorganizePartition{
+		Table:             t,
+		Partition:         p,
+		CurrentPartitions: current,
+		NewPartitions:     new,
+	}
+}
+func (op ReorganizePartition) String() string {
+	return fmt.Sprintf("REORGANIZE PARTITION %s INTO %s IN %s", op.CurrentPartitions.names(), op.NewPartitions.names(), op.Table.Name)
+}
+func (op ReorganizePartition) Query() string {
+	return fmt.Sprintf("ALTER TABLE %s REORGANIZE PARTITION %s INTO %s",
+		op.Table.Name, op.CurrentPartitions.names(), op.NewPartitions.query(op.Partition))
+}
+func (op ReorganizePartition) RollBack() string {
+	return NewReorganizePartition(op.Table, op.Partition, op.NewPartitions, op.CurrentPartitions).Query()
+}
+
+type ResizePartition struct {
+	Table            Table
+	CurrentPartition Partition
+	NewPartition     Partition
+}
+
+func NewResizePartition(t Table, current, new Partition) ResizePartition {
+	return ResizePartition{
+		Table:            t,
+		CurrentPartition: current,
+		NewPartition:     new,
+	}
+}
+func (op ResizePartition) String() string {
+	return fmt.Sprintf("RESIZE PARTITIONS %d TO %d IN %s", op.CurrentPartition.Count, op.NewPartition.Count, op.Table.Name)
+}
+func (op ResizePartition) Query() string {
+	if op.NewPartition.Count > op.CurrentPartition.Count {
+		return fmt.Sprintf("ALTER TABLE %s ADD PARTITION PARTITIONS %d", op.Table.Name, op.NewPartition.Count-op.CurrentPartition.Count)
+	}
+	return fmt.Sprintf("ALTER TABLE %s COALESCE PARTITION %d", op.Table.Name, op.CurrentPartition.Count-op.NewPartition.Count)
+}
+func (op ResizePartition) RollBack() string {
+	return NewResizePartition(op.Table, op.NewPartition, op.CurrentPartition).Query()
+}
@@ -1,6 +1,10 @@
 package migo
 
-import "github.com/urfave/cli"
+import (
+	"fmt"
+
+	"github.com/urfave/cli"
+)
 
 type WaitOption struct {
 	ConfigFile  string
@@ -113,12 +117,148 @@ func NewInitOption(c *cli.Context) (InitOption, error) {
 	return op, nil
 }
 
+type PartitionOption struct {
+	ConfigFile  string
+	StateFile   string
+	Environment string
+	PlanOnly    bool
+}
+
+func NewPartitionOption(c *cli.Context) (PartitionOption, error) {
+	op := PartitionOption{
+		ConfigFile:  c.GlobalString("database"),
+		StateFile:   c.GlobalString("state"),
+		Environment: c.GlobalString("environment"),
+		PlanOnly:    c.Bool("plan"),
+	}
+	if op.ConfigFile == "" {
+		return op, NewOptionEmptyError("database")
+	}
+	if op.StateFile == "" {
+		return op, NewOptionEmptyError("state")
+	}
+	if op.Environment == "" {
+		return op, NewOptionEmptyError("environment")
+	}
+	return op, nil
+}
+
+type DiffOption struct {
+	OldFile     string
+	NewFile     string
+	Revision    string
+	Environment string
+	Format      string
+	Schema      SchemaOption
+}
+
+// NewDiffOption reads the old and new schema files from the arguments. With
+// the revision, the only argument is read at the revision and as it is.
+func NewDiffOption(c *cli.Context) (DiffOption, error) {
+	op := DiffOption{
+		Revision:    c.String("rev"),
+		Environment: c.GlobalString("environment"),
+		Format:      c.String("format"),
+		Schema:      NewSchemaOption(c),
+	}
+
+	args := c.Args()
+	switch {
+	case op.Revision != "" && len(args) == 1:
+		op.OldFile, op.NewFile = args[0], args[0]
+	case op.Revision == "" && len(args) == 2:
+		op.OldFile, op.NewFile = args[0], args[1]
+	default:
+		return op, NewOptionEmptyError("schema")
+	}
+	return op, nil
+}
+
+type DDLOption struct {
+	Migrate   MigrateOption
+	StateFile string
+}
+
+// NewDDLOption reads the schema file, or only the state file with --from-state.
+func NewDDLOption(c *cli.Context) (DDLOption, error) {
+	op := DDLOption{Migrate: MigrateOption{Environment: c.GlobalString("environment")}}
+	if c.Bool("from-state") {
+		if err := op.Migrate.SetStateFile(c.GlobalString("state")); err != nil {
+			return op, err
+		}
+		op.StateFile = op.Migrate.StateFile
+		return op, nil
+	}
+	if err := op.Migrate.setSchema(c); err != nil {
+		return op, err
+	}
+	return op, nil
+}
+
+type ImportOption struct {
+	SQLFile string
+}
+
+func NewImportOption(c *cli.Context) (ImportOption, error) {
+	op := ImportOption{SQLFile: c.String("from-sql")}
+	if op.SQLFile == "" {
+		return op, NewOptionEmptyError("from-sql")
+	}
+	return op, nil
+}
+
+type CompareOption struct {
+	ConfigFile string
+	Source     string
+	Target     string
+	Format     string
+}
+
+// NewCompareOption reads the source and target environments from the
+// environment flags of the command, given in this order.
+func NewCompareOption(c *cli.Context) (CompareOption, error) {
+	op := CompareOption{Format: c.String("format")}
+	if op.ConfigFile = c.GlobalString("database"); op.ConfigFile == "" {
+		return op, NewOptionEmptyError("database")
+	}
+
+	envs := c.StringSlice("environment")
+	if len(envs) != 2 {
+		return op, fmt.Errorf("compare needs two environments, but %d given", len(envs))
+	}
+	op.Source, op.Target = envs[0], envs[1]
+	return op, nil
+}
+
+type SchemaOption struct {
+	CommentFromDescription bool
+	CheckConstraint        bool
+	JSONSchemaCheck        bool
+	Dialect                string
+	TypeMappingFile        string
+	typeMapping            TypeMapping
+}
+
+func NewSchemaOption(c *cli.Context) SchemaOption {
+	return SchemaOption{
+		CommentFromDescription: c.GlobalBool("comment-from-description"),
+		CheckConstraint:        c.GlobalBool("check-constraint"),
+		JSONSchemaCheck:        c.GlobalBool("json-schema-check"),
+		Dialect:                c.GlobalString("dialect"),
+		TypeMappingFile:        c.GlobalString("type-mapping"),
+	}
+}
+
 type MigrateOption struct {
 	FormatType  string
 	ConfigFile  string
 	StateFile   string
 	SchemaFile  string
 	Environment string
+	Schema      SchemaOption
+	Expand      bool
+	Contract    bool
+	HooksFile   string
 }
 
 func (op *MigrateOption) SetJSONFormatSchema(schema string) {
@@ -155,15 +295,14 @@ func (op *MigrateOption) SetEnvironment(env string) error {
 	return nil
 }
 
-func NewMigrateOption(c *cli.Context) (MigrateOption, error) {
-	op := MigrateOption{}
+func (op *MigrateOption) setSchema(c *cli.Context) error {
 	j, y := c.GlobalString("json"), c.GlobalString("yaml")
 	if j == "" && y == "" {
-		return op, NewOptionEmptyError("schema")
+		return NewOptionEmptyError("schema")
 	}
 
 	if j != "" && y != "" {
-		return op, NewMigrateOptionInvalidError()
+		return NewMigrateOptionInvalidError()
 	}
 
 	if j != "" {
@@ -172,6 +311,35 @@ func NewMigrateOption(c *cli.Context) (MigrateOption, error) {
 	if y != "" {
 		op.SetYAMLFormatSchema(y)
 	}
+	op.Schema = NewSchemaOption(c)
+	return nil
+}
+
+type ValidateOption struct {
+	Migrate   MigrateOption
+	RulesFile string
+	Format    string
+}
+
+// NewValidateOption returns the option to read the schema, which needs neither
+// the state nor the database configuration.
+func NewValidateOption(c *cli.Context) (ValidateOption, error) {
+	op := ValidateOption{
+		Migrate:   MigrateOption{Environment: c.GlobalString("environment")},
+		RulesFile: c.String("rules"),
+		Format:    c.String("format"),
+	}
+	if err := op.Migrate.setSchema(c); err != nil {
+		return op, err
+	}
+	return op, nil
+}
+
+func NewMigrateOption(c *cli.Context) (MigrateOption, error) {
+	op := MigrateOption{}
+	if err := op.setSchema(c); err != nil {
+		return op, err
+	}
 
 	state, db, env := c.GlobalString("state"), c.GlobalString("database"), c.GlobalString("environment")
 	if err := op.SetStateFile(state); err != nil {
@@ -183,6 +351,8 @@ func NewMigrateOption(c *cli.Context) (MigrateOption, error) {
 	if err := op.SetEnvironment(env); err != nil {
 		return op, err
 	}
+	op.Expand, op.Contract = c.GlobalBool("expand-contract"), c.GlobalBool("contract")
+	op.HooksFile = c.GlobalString("hooks")
 
 	return op, nil
 }
@@ -0,0 +1,137 @@
+package migo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	hschema "github.com/lestrrat/go-jshschema"
+	"github.com/pkg/errors"
+)
+
+// OverlayFile returns the schema file of the environment merged over the
+// schema file, e.g. schema.production.yml for schema.yml.
+// For the schema directory, it is the file named after the directory, e.g. schema.production.yml for schema/.
+func (op MigrateOption) OverlayFile() string {
+	if op.SchemaFile == "" || op.Environment == "" || strings.Contains(op.SchemaFile, ",") {
+		return ""
+	}
+	if isDir(op.SchemaFile) {
+		return filepath.Clean(op.SchemaFile) + "." + op.Environment + ".yml"
+	}
+	ext := filepath.Ext(op.SchemaFile)
+	return strings.TrimSuffix(op.SchemaFile, ext) + "." + op.Environment + ext
+}
+
+func (op MigrateOption) hasOverlay() bool {
+	f := op.OverlayFile()
+	if f == "" {
+		return false
+	}
+	_, err := os.Stat(f)
+	return err == nil
+}
+
+func readSchemaMap(filePath string) (map[string]interface{}, error) {
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "schema file open error")
+	}
+
+	m := map[string]interface{}{}
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil, errors.Wrap(err, "schema file parse error")
+	}
+	return m, nil
+}
+
+// mergePatch merges patch over target with the JSON merge patch (RFC 7386) semantics,
+// where null removes the key and any value other than an object replaces the target.
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], v)
+	}
+	return t
+}
+
+func readOverlaidSchema(op MigrateOption) (*hschema.HyperSchema, error) {
+	var base map[string]interface{}
+	var err error
+	if op.isMultiFile() {
+		base, err = readMultiFileSchemaMap(op)
+	} else {
+		base, err = readSchemaMap(op.SchemaFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	overlay, err := readSchemaMap(op.OverlayFile())
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading overlay %s", op.OverlayFile())
+	}
+
+	m, ok := mergePatch(base, overlay).(map[string]interface{})
+	if !ok {
+		return nil, errors.New("fail to convert type to map[string]interface{}")
+	}
+	h := hschema.New()
+	if err := h.Extract(m); err != nil {
+		return nil, errors.Wrapf(err, "merging overlay %s", op.OverlayFile())
+	}
+	return h, nil
+}
+
+// AttributeOverlay marks the operations not in the operations planned
+// from the schema without the overlay as contributed by the overlay.
+func (ops *Operations) AttributeOverlay(base Operations, overlay string) {
+	queries := map[string]bool{}
+	for _, op := range base.Operation {
+		queries[op.Query()] = true
+	}
+
+	ops.overlaid = map[string]string{}
+	for _, op := range ops.Operation {
+		if !queries[op.Query()] {
+			ops.overlaid[op.Query()] = overlay
+		}
+	}
+}
+
+// NewOverlaidOperations returns the operations from current state to the schema
+// merged with the overlay of the environment, and which of them the overlay contributes.
+func NewOverlaidOperations(current State, new *State, op MigrateOption) (Operations, error) {
+	ops, err := NewPhasedOperations(current, new, op)
+	if err != nil || !op.hasOverlay() {
+		return ops, err
+	}
+
+	h, err := ReadBaseSchema(op)
+	if err != nil {
+		return ops, errors.Wrap(err, "parsing hyper-schema without overlay")
+	}
+	base, err := NewStateFromSchema(h, op.Schema)
+	if err != nil {
+		return ops, errors.Wrap(err, "parsing state without overlay")
+	}
+	bops, err := NewPhasedOperations(current, &base, op)
+	if err != nil {
+		return ops, errors.Wrap(err, "creating requests without overlay")
+	}
+	ops.AttributeOverlay(bops, op.OverlayFile())
+	return ops, nil
+}
@@ -0,0 +1,263 @@
+package migo
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Partition is the partitioning scheme of a table. The table is not partitioned
+// when Type is empty.
+type Partition struct {
+	Type       string               `json:"type"`
+	Columns    []string             `json:"columns"`
+	Expression string               `json:"expression"`
+	Count      int                  `json:"count"`
+	Partitions PartitionDefinitions `json:"partitions"`
+	Policy     PartitionPolicy      `json:"policy"`
+}
+
+// PartitionDefinition is a partition of a RANGE or LIST partitioned table,
+// whose values are after LESS THAN or IN.
+type PartitionDefinition struct {
+	Name   string `json:"name"`
+	Values string `json:"values"`
+}
+
+type PartitionDefinitions []PartitionDefinition
+
+func (p Partition) isPartitioned() bool {
+	return p.Type != ""
+}
+
+func (p Partition) isRangeOrList() bool {
+	switch strings.ToUpper(p.Type) {
+	case "RANGE", "LIST":
+		return true
+	}
+	return false
+}
+
+func (t Table) findPartition(m map[string]interface{}) (Partition, error) {
+	p := Partition{}
+	if m["partition"] == nil {
+		return p, nil
+	}
+	if err := convert(m["partition"], &p); err != nil {
+		return p, errors.Wrap(err, "convert to partition")
+	}
+
+	cs := []string{}
+	for _, id := range p.Columns {
+		c, err := t.findColumnWithID(id)
+		if err != nil {
+			return p, errors.Wrapf(err, "searching partition column %s", id)
+		}
+		cs = append(cs, c.Name)
+	}
+	p.Columns = cs
+
+	if err := p.validate(); err != nil {
+		return p, err
+	}
+	if p.Policy.isDeclared() {
+		if err := p.Policy.validate(p); err != nil {
+			return p, err
+		}
+	}
+	return p, t.validatePartition(p)
+}
+
+func (p Partition) validate() error {
+	if (len(p.Columns) == 0) == (p.Expression == "") {
+		return errors.New("partition needs either columns or expression")
+	}
+
+	switch strings.ToUpper(p.Type) {
+	case "RANGE", "LIST":
+		if len(p.Partitions) == 0 {
+			return fmt.Errorf("%s partition needs partitions", p.Type)
+		}
+		if p.Count != 0 {
+			return fmt.Errorf("%s partition can not have count", p.Type)
+		}
+	case "HASH", "KEY":
+		if p.Count < 1 {
+			return fmt.Errorf("%s partition needs count", p.Type)
+		}
+		if len(p.Partitions) != 0 {
+			return fmt.Errorf("%s partition can not have partitions", p.Type)
+		}
+	default:
+		return fmt.Errorf("partition type %s is invalid", p.Type)
+	}
+	if strings.ToUpper(p.Type) == "KEY" && p.Expression != "" {
+		return errors.New("key partition can not have expression")
+	}
+
+	names := map[string]bool{}
+	for _, d := range p.Partitions {
+		if d.Name == "" || d.Values == "" {
+			return errors.New("partition needs name and values")
+		}
+		if names[d.Name] {
+			return fmt.Errorf("partition %s is not unique", d.Name)
+		}
+		names[d.Name] = true
+	}
+	return nil
+}
+
+// partitionColumns returns the columns in the partition key, which are the columns
+// named in the partition expression if it is used.
+func (t Table) partitionColumns(p Partition) []string {
+	if p.Expression == "" {
+		return p.Columns
+	}
+	cs := []string{}
+	for _, c := range t.Column {
+		if regexp.MustCompile(`\b` + regexp.QuoteMeta(c.Name) + `\b`).MatchString(p.Expression) {
+			cs = append(cs, c.Name)
+		}
+	}
+	return cs
+}
+
+// validatePartition checks that every column in the partition key is part of
+// every unique key of the table, as MySQL requires.
+func (t Table) validatePartition(p Partition) error {
+	keys := [][]string{}
+	for _, k := range t.PrimaryKey {
+		cs := []string{}
+		for _, c := range k.Target {
+			cs = append(cs, c.Name)
+		}
+		keys = append(keys, cs)
+	}
+	for _, c := range t.Column {
+		if c.Unique {
+			keys = append(keys, []string{c.Name})
+		}
+	}
+
+	for _, pc := range t.partitionColumns(p) {
+		for _, k := range keys {
+			found := false
+			for _, c := range k {
+				found = found || c == pc
+			}
+			if !found {
+				return fmt.Errorf("partition column %s is not part of unique key (%s) in table %s", pc, strings.Join(k, ", "), t.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (p Partition) isSchemeUpdatedFrom(target Partition) bool {
+	return !strings.EqualFold(p.Type, target.Type) ||
+		!reflect.DeepEqual(p.Columns, target.Columns) ||
+		p.Expression != target.Expression
+}
+
+func (d PartitionDefinition) query(p Partition) string {
+	if strings.ToUpper(p.Type) == "LIST" {
+		return fmt.Sprintf("PARTITION %s VALUES IN (%s)", d.Name, d.Values)
+	}
+	if strings.ToUpper(d.Values) == "MAXVALUE" {
+		return fmt.Sprintf("PARTITION %s VALUES LESS THAN MAXVALUE", d.Name)
+	}
+	return fmt.Sprintf("PARTITION %s VALUES LESS THAN (%s)", d.Name, d.Values)
+}
+
+func (ds PartitionDefinitions) query(p Partition) string {
+	qs := []string{}
+	for _, d := range ds {
+		qs = append(qs, d.query(p))
+	}
+	return fmt.Sprintf("(%s)", strings.Join(qs, ", "))
+}
+
+func (ds PartitionDefinitions) names() string {
+	ns := []string{}
+	for _, d := range ds {
+		ns = append(ns, d.Name)
+	}
+	return strings.Join(ns, ", ")
+}
+
+func (p Partition) query() string {
+	key := fmt.Sprintf("(%s)", p.Expression)
+	if p.Expression == "" {
+		key = fmt.Sprintf("(%s)", strings.Join(p.Columns, ", "))
+		if p.isRangeOrList() {
+			key = " COLUMNS" + key
+		}
+	}
+
+	q := fmt.Sprintf("PARTITION BY %s%s", strings.ToUpper(p.Type), key)
+	if p.isRangeOrList() {
+		return fmt.Sprintf("%s %s", q, p.Partitions.query(p))
+	}
+	return fmt.Sprintf("%s PARTITIONS %d", q, p.Count)
+}
+
+// UpdatePartition plans the change of the partitions. A new scheme repartitions the table.
+// With the same RANGE or LIST scheme, new partitions at the tail are added, the partitions
+// from the first changed one are reorganized, and expired partitions at the head are dropped.
+// The partitions maintained by a policy are left to MaintainPartitions.
+func (ops *Operations) UpdatePartition(t Table, current, new Partition) {
+	if !current.isPartitioned() && !new.isPartitioned() {
+		return
+	}
+	if current.isSchemeUpdatedFrom(new) {
+		ops.Operation = append(ops.Operation, NewPartitionTable(t, current, new))
+		return
+	}
+
+	if !new.isRangeOrList() {
+		if current.Count != new.Count {
+			ops.Operation = append(ops.Operation, NewResizePartition(t, current, new))
+		}
+		return
+	}
+	if new.Policy.isDeclared() {
+		return
+	}
+	ops.updatePartitionDefinitions(t, current, new)
+}
+
+func (ops *Operations) updatePartitionDefinitions(t Table, current, new Partition) {
+	kept := map[string]bool{}
+	for _, d := range new.Partitions {
+		kept[d.Name] = true
+	}
+	cur := current.Partitions
+	expired := PartitionDefinitions{}
+	for len(cur) > 0 && !kept[cur[0].Name] {
+		expired = append(expired, cur[0])
+		cur = cur[1:]
+	}
+
+	i := 0
+	for i < len(cur) && i < len(new.Partitions) && cur[i] == new.Partitions[i] {
+		i++
+	}
+	switch {
+	case i == len(cur) && i == len(new.Partitions):
+	case i == len(cur):
+		ops.Operation = append(ops.Operation, NewAddPartition(t, new, new.Partitions[i:]))
+	case i == len(new.Partitions):
+		ops.Operation = append(ops.Operation, NewDropPartition(t, new, cur[i:]))
+	default:
+		ops.Operation = append(ops.Operation, NewReorganizePartition(t, new, cur[i:], new.Partitions[i:]))
+	}
+
+	// expired partitions are dropped last, as the last partition can not be dropped
+	if len(expired) > 0 {
+		ops.Operation = append(ops.Operation, NewDropPartition(t, new, expired))
+	}
+}
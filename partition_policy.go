@@ -0,0 +1,189 @@
+package migo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PartitionPolicy keeps RANGE partitions by the interval, adding the partitions
+// Ahead of now and dropping the ones older than Retention intervals.
+type PartitionPolicy struct {
+	Interval  string `json:"interval"`
+	Ahead     int    `json:"ahead"`
+	Retention int    `json:"retention"`
+}
+
+const partitionDateFormat = "2006-01-02"
+
+func (p PartitionPolicy) isDeclared() bool {
+	return p.Interval != ""
+}
+
+func (p PartitionPolicy) validate(partition Partition) error {
+	switch p.Interval {
+	case "day", "month", "year":
+	default:
+		return fmt.Errorf("partition interval %s is invalid", p.Interval)
+	}
+	if strings.ToUpper(partition.Type) != "RANGE" || len(partition.Columns) != 1 {
+		return errors.New("partition policy needs range partition by a single column")
+	}
+	if p.Ahead < 0 || p.Retention < 0 {
+		return errors.New("partition ahead and retention should not be negative")
+	}
+	return nil
+}
+
+// truncate returns the start of the interval including t.
+func (p PartitionPolicy) truncate(t time.Time) time.Time {
+	switch p.Interval {
+	case "day":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (p PartitionPolicy) add(t time.Time, n int) time.Time {
+	switch p.Interval {
+	case "day":
+		return t.AddDate(0, 0, n)
+	case "month":
+		return t.AddDate(0, n, 0)
+	}
+	return t.AddDate(n, 0, 0)
+}
+
+// definition returns the partition of the interval starting at t, named
+// after the start, e.g. p202601 holding the rows less than '2026-02-01'.
+func (p PartitionPolicy) definition(t time.Time) PartitionDefinition {
+	layout := "2006"
+	switch p.Interval {
+	case "day":
+		layout = "20060102"
+	case "month":
+		layout = "200601"
+	}
+	return PartitionDefinition{
+		Name:   "p" + t.Format(layout),
+		Values: fmt.Sprintf("'%s'", p.add(t, 1).Format(partitionDateFormat)),
+	}
+}
+
+// bound returns the date given in the values of the partition.
+func (d PartitionDefinition) bound() (time.Time, bool) {
+	t, err := time.Parse(partitionDateFormat, strings.Trim(d.Values, "'\""))
+	return t, err == nil
+}
+
+func (d PartitionDefinition) isMaxValue() bool {
+	return strings.ToUpper(d.Values) == "MAXVALUE"
+}
+
+// maintain returns the partition with the expired partitions removed and the
+// partitions up to Ahead intervals of now added before MAXVALUE.
+func (p Partition) maintain(now time.Time) Partition {
+	policy := p.Policy
+	start := policy.truncate(now)
+	expiry := policy.add(start, -policy.Retention)
+	last := start
+
+	ds := PartitionDefinitions{}
+	max := PartitionDefinitions{}
+	for _, d := range p.Partitions {
+		if d.isMaxValue() {
+			max = append(max, d)
+			continue
+		}
+		b, ok := d.bound()
+		if ok && policy.Retention > 0 && !b.After(expiry) {
+			continue
+		}
+		if ok && b.After(last) {
+			last = b
+		}
+		ds = append(ds, d)
+	}
+
+	for t := last; !t.After(policy.add(start, policy.Ahead)); t = policy.add(t, 1) {
+		ds = append(ds, policy.definition(t))
+	}
+
+	m := p
+	m.Partitions = append(ds, max...)
+	return m
+}
+
+// inheritMaintainedPartitions keeps the partitions maintained by the policy in the
+// current state, as they are not listed in the schema.
+func (s *State) inheritMaintainedPartitions(current State) {
+	for i, t := range s.Tables {
+		if !t.Partition.Policy.isDeclared() {
+			continue
+		}
+		c, err := current.findTable(t)
+		if err != nil || c.Partition.isSchemeUpdatedFrom(t.Partition) {
+			continue
+		}
+		s.Tables[i].Partition.Partitions = c.Partition.Partitions
+	}
+}
+
+// NewMaintenanceOperations returns the operations to maintain the partitions of
+// the tables with a partition policy at now, and the state after them.
+func NewMaintenanceOperations(current State, now time.Time) (Operations, State, error) {
+	ops := Operations{}
+	new := current
+	new.Tables = append(Tables{}, current.Tables...)
+	for i, t := range new.Tables {
+		if !t.Partition.Policy.isDeclared() {
+			continue
+		}
+		if err := t.Partition.Policy.validate(t.Partition); err != nil {
+			return ops, new, errors.Wrapf(err, "maintaining partitions of %s", t.Name)
+		}
+		new.Tables[i].Partition = t.Partition.maintain(now)
+		ops.updatePartitionDefinitions(t, t.Partition, new.Tables[i].Partition)
+	}
+	return ops, new, nil
+}
+
+func MaintainPartitions(op PartitionOption) error {
+	db, err := NewDB(op.ConfigFile, op.Environment)
+	if err != nil {
+		return err
+	}
+
+	old, err := NewStateFromYAML(op.StateFile)
+	if err != nil {
+		return errors.Wrap(err, "State YAML file parse error")
+	}
+
+	ops, new, err := NewMaintenanceOperations(old, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "creating requests")
+	}
+	Announce(ops, db)
+	if op.PlanOnly {
+		return nil
+	}
+
+	mysql, err := db.open()
+	if err != nil {
+		return err
+	}
+	defer mysql.Close()
+
+	if err := db.migrate(mysql, ops, func(Operation) error { return nil }); err != nil {
+		return err
+	}
+	new.DB = db
+	if err = new.save(op.StateFile); err != nil {
+		return errors.Wrap(err, "saving state file")
+	}
+	return nil
+}
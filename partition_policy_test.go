@@ -0,0 +1,115 @@
+package migo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/meta-closure/migo"
+)
+
+func TestNewMaintenanceOperations(t *testing.T) {
+	type Case struct {
+		input           migo.Partition
+		expectedQueries []string
+		isSuccess       bool
+		spec            string
+	}
+
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	policy := migo.PartitionPolicy{Interval: "month", Ahead: 1, Retention: 3}
+	cases := []Case{
+		{
+			input: migo.Partition{
+				Type:    "range",
+				Columns: []string{"created_at"},
+				Policy:  policy,
+				Partitions: migo.PartitionDefinitions{
+					{Name: "p202606", Values: "'2026-07-01'"},
+					{Name: "p202607", Values: "'2026-08-01'"},
+					{Name: "p202610", Values: "'2026-11-01'"},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE event ADD PARTITION (PARTITION p202611 VALUES LESS THAN ('2026-12-01'))",
+				"ALTER TABLE event DROP PARTITION p202606",
+			},
+			spec:      "add ahead and drop expired partitions",
+			isSuccess: true,
+		},
+		{
+			input: migo.Partition{
+				Type:    "range",
+				Columns: []string{"created_at"},
+				Policy:  policy,
+				Partitions: migo.PartitionDefinitions{
+					{Name: "p202610", Values: "'2026-11-01'"},
+					{Name: "pmax", Values: "MAXVALUE"},
+				},
+			},
+			expectedQueries: []string{
+				"ALTER TABLE event REORGANIZE PARTITION pmax INTO (PARTITION p202611 VALUES LESS THAN ('2026-12-01'), PARTITION pmax VALUES LESS THAN MAXVALUE)",
+			},
+			spec:      "split maxvalue partition",
+			isSuccess: true,
+		},
+		{
+			input: migo.Partition{
+				Type:    "range",
+				Columns: []string{"created_at"},
+				Policy:  policy,
+				Partitions: migo.PartitionDefinitions{
+					{Name: "p202610", Values: "'2026-11-01'"},
+					{Name: "p202611", Values: "'2026-12-01'"},
+				},
+			},
+			expectedQueries: []string{},
+			spec:            "maintained partitions",
+			isSuccess:       true,
+		},
+		{
+			input: migo.Partition{
+				Type:   "hash",
+				Count:  4,
+				Policy: policy,
+			},
+			spec:      "policy of hash partition",
+			isSuccess: false,
+		},
+	}
+
+	for _, c := range cases {
+		s := migo.State{
+			Tables: []migo.Table{
+				{
+					Id:        "#/definitions/event",
+					Name:      "event",
+					Column:    []migo.Column{{Id: "created_at", Name: "created_at", Type: "date"}},
+					Partition: c.input,
+				},
+			},
+		}
+		op, _, err := migo.NewMaintenanceOperations(s, now)
+		if !c.isSuccess && err == nil {
+			t.Errorf("in %s, error is expected but null", c.spec)
+			continue
+		}
+		if c.isSuccess && err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if len(op.Operation) != len(c.expectedQueries) {
+			t.Errorf("in %s, expected query length is %d, but actual %d", c.spec, len(c.expectedQueries), len(op.Operation))
+			continue
+		}
+		for i := range op.Operation {
+			if c.expectedQueries[i] != op.Operation[i].Query() {
+				t.Errorf("in %s, expected query is %s, but actual %s", c.spec, c.expectedQueries[i], op.Operation[i].Query())
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package migo
+
+import (
+	"fmt"
+	"io"
+)
+
+const (
+	FormatSQL = "sql"
+
+	sqlBodyDelimiter = ";;"
+)
+
+type plannedOperation struct {
+	Summary  string `json:"summary"`
+	Query    string `json:"query"`
+	RollBack string `json:"rollback"`
+}
+
+type plan struct {
+	Operations []plannedOperation `json:"operations"`
+	Warnings   []string           `json:"warnings"`
+}
+
+func newPlan(ops Operations) plan {
+	p := plan{Operations: []plannedOperation{}, Warnings: []string{}}
+	for _, op := range ops.Operation {
+		p.Operations = append(p.Operations, plannedOperation{
+			Summary:  op.String(),
+			Query:    op.Query(),
+			RollBack: op.RollBack(),
+		})
+	}
+	p.Warnings = append(p.Warnings, ops.Warning...)
+	return p
+}
+
+// hasCompoundBody reports whether the query of the operation has a body which
+// may contain semicolons, and needs another delimiter in the mysql client.
+func hasCompoundBody(op Operation) bool {
+	switch op.(type) {
+	case CreateTrigger, CreateRoutine, CreateEvent:
+		return true
+	}
+	return false
+}
+
+// Write prints the operations in the plan output format, text, json or sql.
+func (ops Operations) Write(w io.Writer, format string) error {
+	switch format {
+	case "", FormatText:
+		for _, op := range ops.Operation {
+			fmt.Fprintln(w, op.String())
+		}
+		for _, warning := range ops.Warning {
+			fmt.Fprintf(w, "WARNING: %s\n", warning)
+		}
+		return nil
+	case FormatJSON:
+		return writeJSON(w, newPlan(ops))
+	case FormatSQL:
+		for _, warning := range ops.Warning {
+			fmt.Fprintf(w, "-- WARNING: %s\n", warning)
+		}
+		for _, op := range ops.Operation {
+			if !hasCompoundBody(op) {
+				fmt.Fprintf(w, "%s;\n", op.Query())
+				continue
+			}
+			fmt.Fprintf(w, "DELIMITER %s\n%s%s\nDELIMITER ;\n", sqlBodyDelimiter, op.Query(), sqlBodyDelimiter)
+		}
+		return nil
+	}
+	return fmt.Errorf("format %s is invalid", format)
+}
@@ -0,0 +1,210 @@
+package migo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type Routine struct {
+	Name            string   `json:"name"`
+	Type            string   `json:"type"`
+	Parameters      []string `json:"parameters"`
+	Returns         string   `json:"returns"`
+	Characteristics string   `json:"characteristics"`
+	Body            string   `json:"body"`
+	Checksum        string   `json:"checksum"`
+}
+
+type Routines []Routine
+
+func (r Routines) Len() int {
+	return len(r)
+}
+
+func (r Routines) Less(i, j int) bool {
+	return r[i].Name < r[j].Name
+}
+
+func (r Routines) Swap(i, j int) {
+	r[i], r[j] = r[j], r[i]
+}
+
+func NewRoutine(name string) Routine {
+	return Routine{Name: name}
+}
+
+type Event struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	Body     string `json:"body"`
+	Checksum string `json:"checksum"`
+}
+
+type Events []Event
+
+func (e Events) Len() int {
+	return len(e)
+}
+
+func (e Events) Less(i, j int) bool {
+	return e[i].Name < e[j].Name
+}
+
+func (e Events) Swap(i, j int) {
+	e[i], e[j] = e[j], e[i]
+}
+
+func NewEvent(name string) Event {
+	return Event{Name: name}
+}
+
+func checksum(s string) string {
+	b := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(b[:])
+}
+
+func convert(i interface{}, v interface{}) error {
+	b, err := json.Marshal(i)
+	if err != nil {
+		return errors.Wrap(err, "convert to json")
+	}
+	return json.Unmarshal(b, v)
+}
+
+// readOnlyFields are the fields of routines and events which migo sets
+// itself: the name comes from the key and the checksum from the definition.
+var readOnlyFields = []string{"name", "checksum"}
+
+func findReadOnlyField(i interface{}) error {
+	m, ok := i.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for _, f := range readOnlyFields {
+		if _, ok := m[f]; ok {
+			return fmt.Errorf("%s can not be given", f)
+		}
+	}
+	return nil
+}
+
+func (r *Routine) read(i interface{}) error {
+	if err := findReadOnlyField(i); err != nil {
+		return err
+	}
+	if err := convert(i, r); err != nil {
+		return errors.Wrap(err, "convert to routine")
+	}
+
+	switch strings.ToUpper(r.Type) {
+	case "PROCEDURE":
+		if r.Returns != "" {
+			return errors.New("procedure can not have returns")
+		}
+	case "FUNCTION":
+		if r.Returns == "" {
+			return errors.New("returns is not found")
+		}
+	default:
+		return fmt.Errorf("type %s is invalid", r.Type)
+	}
+	if r.Body == "" {
+		return errors.New("body is not found")
+	}
+
+	r.Checksum = checksum(r.query())
+	return nil
+}
+
+func (r Routine) isUpdatedFrom(target Routine) (bool, error) {
+	if r.Name != target.Name {
+		return false, errors.New("the target routine name is wrong")
+	}
+	return r.Checksum != target.Checksum, nil
+}
+
+func (r Routine) query() string {
+	s := []string{fmt.Sprintf("%s %s(%s)", strings.ToUpper(r.Type), r.Name, strings.Join(r.Parameters, ", "))}
+	if r.Returns != "" {
+		s = append(s, fmt.Sprintf("RETURNS %s", r.Returns))
+	}
+	if r.Characteristics != "" {
+		s = append(s, r.Characteristics)
+	}
+	s = append(s, r.Body)
+	return strings.Join(s, " ")
+}
+
+func (e *Event) read(i interface{}) error {
+	if err := findReadOnlyField(i); err != nil {
+		return err
+	}
+	if err := convert(i, e); err != nil {
+		return errors.Wrap(err, "convert to event")
+	}
+	if e.Schedule == "" {
+		return errors.New("schedule is not found")
+	}
+	if e.Body == "" {
+		return errors.New("body is not found")
+	}
+
+	e.Checksum = checksum(e.query())
+	return nil
+}
+
+func (e Event) isUpdatedFrom(target Event) (bool, error) {
+	if e.Name != target.Name {
+		return false, errors.New("the target event name is wrong")
+	}
+	return e.Checksum != target.Checksum, nil
+}
+
+func (e Event) query() string {
+	return fmt.Sprintf("EVENT %s ON SCHEDULE %s DO %s", e.Name, e.Schedule, e.Body)
+}
+
+func findRoutines(i interface{}) (Routines, error) {
+	if i == nil {
+		return nil, nil
+	}
+	m, ok := i.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("fail to convert type to map[string]interface{}")
+	}
+
+	rs := Routines{}
+	for k, v := range m {
+		r := NewRoutine(k)
+		if err := r.read(v); err != nil {
+			return nil, errors.Wrapf(err, "reading routine %s", k)
+		}
+		rs = append(rs, r)
+	}
+	return rs, nil
+}
+
+func findEvents(i interface{}) (Events, error) {
+	if i == nil {
+		return nil, nil
+	}
+	m, ok := i.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("fail to convert type to map[string]interface{}")
+	}
+
+	es := Events{}
+	for k, v := range m {
+		e := NewEvent(k)
+		if err := e.read(v); err != nil {
+			return nil, errors.Wrapf(err, "reading event %s", k)
+		}
+		es = append(es, e)
+	}
+	return es, nil
+}
@@ -0,0 +1,186 @@
+package migo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var schemaFileExts = []string{".yml", ".yaml", ".json"}
+
+func isSchemaFile(name string) bool {
+	for _, ext := range schemaFileExts {
+		if filepath.Ext(name) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// isMultiFile returns whether the schema is a directory or a comma separated list of files.
+func (op MigrateOption) isMultiFile() bool {
+	return strings.Contains(op.SchemaFile, ",") || isDir(op.SchemaFile)
+}
+
+// schemaFiles returns the schema files in the directory, or in the comma separated list.
+// Overlays of the environments like user.production.yml next to user.yml are not included.
+func (op MigrateOption) schemaFiles() ([]string, error) {
+	if !isDir(op.SchemaFile) {
+		files := []string{}
+		for _, f := range strings.Split(op.SchemaFile, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				files = append(files, filepath.Clean(f))
+			}
+		}
+		return files, nil
+	}
+
+	fis, err := ioutil.ReadDir(op.SchemaFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "schema directory open error")
+	}
+	names := map[string]bool{}
+	for _, fi := range fis {
+		if !fi.IsDir() && isSchemaFile(fi.Name()) {
+			names[fi.Name()] = true
+		}
+	}
+
+	files := []string{}
+	for name := range names {
+		ext := filepath.Ext(name)
+		base := strings.TrimSuffix(name, ext)
+		if i := strings.LastIndex(base, "."); i >= 0 && names[base[:i]+ext] {
+			continue
+		}
+		files = append(files, filepath.Join(op.SchemaFile, name))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// resolveFileRefs rewrites the $ref and foreign key target_table pointers into
+// another schema file like user.yml#/definitions/user to the pointers into the merged schema.
+func resolveFileRefs(v interface{}, from string, files map[string]bool) error {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			if k == "$ref" {
+				r, err := resolveFileRef(e, from, files)
+				if err != nil {
+					return err
+				}
+				t[k] = r
+				continue
+			}
+			if fk, ok := e.(map[string]interface{}); ok && k == "foreign_key" {
+				r, err := resolveFileRef(fk["target_table"], from, files)
+				if err != nil {
+					return err
+				}
+				if r != nil {
+					fk["target_table"] = r
+				}
+			}
+			if err := resolveFileRefs(e, from, files); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, e := range t {
+			if err := resolveFileRefs(e, from, files); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveFileRef rewrites a pointer into another schema file. Other values are returned as is.
+func resolveFileRef(v interface{}, from string, files map[string]bool) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return v, nil
+	}
+	i := strings.Index(s, "#/")
+	if i <= 0 || !isSchemaFile(s[:i]) {
+		return s, nil
+	}
+	f := filepath.Join(filepath.Dir(from), s[:i])
+	if !files[f] {
+		return nil, fmt.Errorf("%s refers to %s not in the schema files", s, f)
+	}
+	return s[i:], nil
+}
+
+// mergeSchemaMap merges a schema file into the merged schema. Definitions
+// and other objects are merged key by key, and the same key in two files is an error.
+func mergeSchemaMap(dst, src map[string]interface{}, file string, origins map[string]string) error {
+	for k, v := range src {
+		if dst[k] == nil {
+			dst[k] = v
+			origins[k] = file
+			continue
+		}
+
+		d, dok := dst[k].(map[string]interface{})
+		s, sok := v.(map[string]interface{})
+		if !dok || !sok {
+			if !reflect.DeepEqual(dst[k], v) {
+				return fmt.Errorf("%s in %s conflicts with %s", k, file, origins[k])
+			}
+			continue
+		}
+
+		for id, e := range s {
+			key := k + "/" + id
+			if _, ok := d[id]; ok {
+				return fmt.Errorf("duplicate Id #/%s in %s and %s", key, origins[key], file)
+			}
+			d[id] = e
+			origins[key] = file
+		}
+	}
+	return nil
+}
+
+func readMultiFileSchemaMap(op MigrateOption) (map[string]interface{}, error) {
+	files, err := op.schemaFiles()
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("schema file is not found in %s", op.SchemaFile)
+	}
+	loaded := map[string]bool{}
+	for _, f := range files {
+		loaded[f] = true
+	}
+
+	m := map[string]interface{}{}
+	origins := map[string]string{}
+	for _, f := range files {
+		s, err := readSchemaMap(f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", f)
+		}
+		if err := resolveFileRefs(s, f, loaded); err != nil {
+			return nil, errors.Wrapf(err, "resolving references in %s", f)
+		}
+		if err := mergeSchemaMap(m, s, f, origins); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,61 @@
+package migo_test
+
+import (
+	"testing"
+
+	"github.com/meta-closure/migo"
+)
+
+func TestReadSchema(t *testing.T) {
+	type Case struct {
+		input     migo.MigrateOption
+		isSuccess bool
+		spec      string
+	}
+
+	cases := []Case{
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/multi",
+				FormatType: "yaml",
+			},
+			spec:      "correct schema directory",
+			isSuccess: true,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/multi_fail_by_duplicate",
+				FormatType: "yaml",
+			},
+			spec:      "duplicate definition in schema files",
+			isSuccess: false,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/multi_fail_by_ref",
+				FormatType: "yaml",
+			},
+			spec:      "reference to file not in schema files",
+			isSuccess: false,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/multi/source.yml,./test/not_found.yml",
+				FormatType: "yaml",
+			},
+			spec:      "schema file not found",
+			isSuccess: false,
+		},
+	}
+
+	for _, c := range cases {
+		_, err := migo.ReadSchema(c.input)
+		if !c.isSuccess && err == nil {
+			t.Errorf("in %s, error is expected but null", c.spec)
+			continue
+		}
+		if c.isSuccess && err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+		}
+	}
+}
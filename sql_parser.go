@@ -0,0 +1,302 @@
+package migo
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type sqlTokenKind int
+
+const (
+	sqlWord sqlTokenKind = iota
+	sqlIdentifier
+	sqlString
+	sqlNumber
+	sqlSymbol
+)
+
+// sqlToken is a token of a SQL script, where a quoted identifier or string
+// has its quotes removed.
+type sqlToken struct {
+	Kind  sqlTokenKind
+	Value string
+}
+
+// sqlStatement is a statement of a SQL script with the line it starts.
+type sqlStatement struct {
+	Line   int
+	Tokens []sqlToken
+}
+
+// splitSQL splits the script into statements, skipping the comments. The
+// conditional comments of mysqldump are skipped as well.
+func splitSQL(script string) ([]sqlStatement, error) {
+	stmts := []sqlStatement{}
+	r := []rune(script)
+	line := 1
+	current := sqlStatement{Line: line}
+
+	for i := 0; i < len(r); i++ {
+		c := r[i]
+		switch {
+		case c == '\n':
+			line++
+		case unicode.IsSpace(c):
+		case c == '-' && i+1 < len(r) && r[i+1] == '-', c == '#':
+			for i < len(r) && r[i] != '\n' {
+				i++
+			}
+			line++
+		case c == '/' && i+1 < len(r) && r[i+1] == '*':
+			end := strings.Index(string(r[i+2:]), "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: comment is not closed", line)
+			}
+			comment := []rune(string(r[i+2:])[:end])
+			line += strings.Count(string(comment), "\n")
+			i += len(comment) + 3
+		case c == ';':
+			if len(current.Tokens) > 0 {
+				stmts = append(stmts, current)
+			}
+			current = sqlStatement{}
+		case c == '\'' || c == '"' || c == '`':
+			s, n, err := readQuoted(r[i:], c)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", line, err)
+			}
+			kind := sqlString
+			if c == '`' {
+				kind = sqlIdentifier
+			}
+			current = current.add(sqlToken{Kind: kind, Value: s}, line)
+			line += strings.Count(string(r[i:i+n]), "\n")
+			i += n - 1
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(r) && (unicode.IsDigit(r[j]) || r[j] == '.') {
+				j++
+			}
+			current = current.add(sqlToken{Kind: sqlNumber, Value: string(r[i:j])}, line)
+			i = j - 1
+		case unicode.IsLetter(c) || c == '_' || c == '$':
+			j := i
+			for j < len(r) && (unicode.IsLetter(r[j]) || unicode.IsDigit(r[j]) || r[j] == '_' || r[j] == '$') {
+				j++
+			}
+			current = current.add(sqlToken{Kind: sqlWord, Value: string(r[i:j])}, line)
+			i = j - 1
+		default:
+			current = current.add(sqlToken{Kind: sqlSymbol, Value: string(c)}, line)
+		}
+	}
+	if len(current.Tokens) > 0 {
+		stmts = append(stmts, current)
+	}
+	return stmts, nil
+}
+
+func (s sqlStatement) add(t sqlToken, line int) sqlStatement {
+	if len(s.Tokens) == 0 {
+		s.Line = line
+	}
+	s.Tokens = append(s.Tokens, t)
+	return s
+}
+
+// readQuoted reads the quoted string at the head of r, and returns it with
+// the number of runes read.
+func readQuoted(r []rune, q rune) (string, int, error) {
+	s := []rune{}
+	for i := 1; i < len(r); i++ {
+		switch {
+		case r[i] == '\\' && q != '`' && i+1 < len(r):
+			i++
+			s = append(s, r[i])
+		case r[i] == q && i+1 < len(r) && r[i+1] == q:
+			i++
+			s = append(s, q)
+		case r[i] == q:
+			return string(s), i + 1, nil
+		default:
+			s = append(s, r[i])
+		}
+	}
+	return "", 0, fmt.Errorf("%c is not closed", q)
+}
+
+// sqlParser reads the tokens of a statement from the head.
+type sqlParser struct {
+	tokens []sqlToken
+	pos    int
+}
+
+func newSQLParser(ts []sqlToken) *sqlParser {
+	return &sqlParser{tokens: ts}
+}
+
+func (p *sqlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *sqlParser) peek() sqlToken {
+	if p.done() {
+		return sqlToken{Kind: sqlSymbol}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *sqlParser) next() sqlToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// is reports whether the next tokens are the keywords.
+func (p *sqlParser) is(words ...string) bool {
+	for i, w := range words {
+		if p.pos+i >= len(p.tokens) {
+			return false
+		}
+		t := p.tokens[p.pos+i]
+		if t.Kind != sqlWord || !strings.EqualFold(t.Value, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// accept consumes the keywords if the next tokens are them.
+func (p *sqlParser) accept(words ...string) bool {
+	if !p.is(words...) {
+		return false
+	}
+	p.pos += len(words)
+	return true
+}
+
+func (p *sqlParser) isSymbol(s string) bool {
+	t := p.peek()
+	return t.Kind == sqlSymbol && t.Value == s
+}
+
+// name reads an identifier, which may be qualified by the database name.
+func (p *sqlParser) name() (string, error) {
+	t := p.next()
+	if t.Kind != sqlWord && t.Kind != sqlIdentifier {
+		return "", fmt.Errorf("identifier is expected, but %q", t.Value)
+	}
+	if p.isSymbol(".") {
+		p.next()
+		return p.name()
+	}
+	return t.Value, nil
+}
+
+// group reads the tokens enclosed in the parentheses.
+func (p *sqlParser) group() ([]sqlToken, error) {
+	if !p.isSymbol("(") {
+		return nil, fmt.Errorf("( is expected, but %q", p.peek().Value)
+	}
+	start, depth := p.pos+1, 0
+	for !p.done() {
+		t := p.next()
+		if t.Kind != sqlSymbol {
+			continue
+		}
+		switch t.Value {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return p.tokens[start : p.pos-1], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("( is not closed")
+}
+
+// rest returns the tokens not read yet.
+func (p *sqlParser) rest() []sqlToken {
+	if p.done() {
+		return nil
+	}
+	ts := p.tokens[p.pos:]
+	p.pos = len(p.tokens)
+	return ts
+}
+
+// splitSQLTokens splits the tokens at the commas out of parentheses.
+func splitSQLTokens(ts []sqlToken) [][]sqlToken {
+	parts := [][]sqlToken{}
+	start, depth := 0, 0
+	for i, t := range ts {
+		if t.Kind != sqlSymbol {
+			continue
+		}
+		switch t.Value {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case ",":
+			if depth == 0 {
+				parts = append(parts, ts[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, ts[start:])
+}
+
+// sqlColumnNames reads the column list of an index, and returns the parts
+// which have the prefix length or the order ignored.
+func sqlColumnNames(ts []sqlToken) ([]string, []string, error) {
+	names, ignored := []string{}, []string{}
+	for _, part := range splitSQLTokens(ts) {
+		p := newSQLParser(part)
+		name, err := p.name()
+		if err != nil {
+			return nil, nil, err
+		}
+		if !p.done() {
+			ignored = append(ignored, renderSQL(part))
+		}
+		names = append(names, name)
+	}
+	return names, ignored, nil
+}
+
+// renderSQL joins the tokens into the SQL text.
+func renderSQL(ts []sqlToken) string {
+	b := &strings.Builder{}
+	for i, t := range ts {
+		if i > 0 && needsSpace(ts[i-1], t) {
+			b.WriteString(" ")
+		}
+		switch t.Kind {
+		case sqlString:
+			b.WriteString(quote(t.Value))
+		case sqlIdentifier:
+			b.WriteString("`" + t.Value + "`")
+		default:
+			b.WriteString(t.Value)
+		}
+	}
+	return b.String()
+}
+
+func needsSpace(prev, t sqlToken) bool {
+	if prev.Kind == sqlSymbol && (prev.Value == "(" || prev.Value == ".") {
+		return false
+	}
+	if t.Kind == sqlSymbol && (t.Value == ")" || t.Value == "," || t.Value == ".") {
+		return false
+	}
+	if t.Kind == sqlSymbol && t.Value == "(" {
+		return prev.Kind == sqlSymbol
+	}
+	return true
+}
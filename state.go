@@ -12,10 +12,15 @@ import (
 )
 
 type State struct {
-	DB         DB          `json:"db"`
-	Tables     Tables      `json:"tables"`
-	ForeignKey ForeignKeys `json:"foreign_key"`
-	UpdatedAt  time.Time   `json:"updated_at"`
+	DB               DB          `json:"db"`
+	Tables           Tables      `json:"tables"`
+	ForeignKey       ForeignKeys `json:"foreign_key"`
+	View             Views       `json:"view"`
+	Routine          Routines    `json:"routine"`
+	Event            Events      `json:"event"`
+	Contract         Contracts   `json:"contract"`
+	AppliedDataSteps []string    `json:"applied_data_steps"`
+	UpdatedAt        time.Time   `json:"updated_at"`
 }
 
 func NewState() State {
@@ -157,15 +162,29 @@ func findForeingKey(root *hschema.HyperSchema, s State) ([]ForeignKey, error) {
 	return fks, nil
 }
 
-func NewStateFromSchema(root *hschema.HyperSchema) (State, error) {
+func NewStateFromSchema(root *hschema.HyperSchema, op SchemaOption) (State, error) {
+	if err := validateMetaSchema(root); err != nil {
+		return NewState(), err
+	}
+	return readState(root, op)
+}
+
+// readState builds the state from the schema without checking the
+// extension keywords against the meta-schema, ignoring unknown keys.
+func readState(root *hschema.HyperSchema, op SchemaOption) (State, error) {
 	var err error
 	s := NewState()
+	op.typeMapping, err = NewTypeMapping(op.Dialect, op.TypeMappingFile)
+	if err != nil {
+		return s, errors.Wrap(err, "reading type mapping")
+	}
+
 	for k, v := range root.Definitions {
 		if hasNotTable(v) {
 			continue
 		}
 		t := NewTable(definitonsID(k))
-		if err := t.read(v); err != nil {
+		if err := t.read(v, op); err != nil {
 			return s, errors.Wrap(err, "in definitions")
 		}
 		s.Tables = append(s.Tables, *t)
@@ -176,12 +195,35 @@ func NewStateFromSchema(root *hschema.HyperSchema) (State, error) {
 			continue
 		}
 		t := NewTable(propertiesID(k))
-		if t.read(v); err != nil {
+		if err := t.read(v, op); err != nil {
 			return s, errors.Wrap(err, "in properties")
 		}
 		s.Tables = append(s.Tables, *t)
 	}
 
+	for k, v := range root.Definitions {
+		if hasNotView(v) {
+			continue
+		}
+		view := NewView(definitonsID(k))
+		if err := view.read(v); err != nil {
+			return s, errors.Wrapf(err, "reading view %s in definitions", k)
+		}
+		if err := view.resolve(s); err != nil {
+			return s, errors.Wrapf(err, "fail to resolve dependency of view %s", k)
+		}
+		s.View = append(s.View, *view)
+	}
+
+	s.Routine, err = findRoutines(root.Extras["routine"])
+	if err != nil {
+		return s, errors.Wrap(err, "reading routines")
+	}
+	s.Event, err = findEvents(root.Extras["event"])
+	if err != nil {
+		return s, errors.Wrap(err, "reading events")
+	}
+
 	fks, err := findForeingKey(root, s)
 	if err != nil {
 		return s, errors.Wrap(err, "searching foreing key")
@@ -199,6 +241,9 @@ func NewStateFromSchema(root *hschema.HyperSchema) (State, error) {
 func (s State) Sort() State {
 	sort.Sort(s.ForeignKey)
 	sort.Sort(s.Tables)
+	sort.Sort(s.View)
+	sort.Sort(s.Routine)
+	sort.Sort(s.Event)
 	for i := range s.Tables {
 		sort.Sort(s.Tables[i].Column)
 		sort.Sort(s.Tables[i].PrimaryKey)
@@ -209,6 +254,9 @@ func (s State) Sort() State {
 		for t := range s.Tables[i].Index {
 			sort.Sort(s.Tables[i].Index[t].Target)
 		}
+		sort.Sort(s.Tables[i].Check)
+		sort.Sort(s.Tables[i].Trigger)
+		sort.Sort(s.Tables[i].Data)
 	}
 	return s
 }
@@ -249,8 +297,59 @@ func (s State) findTableWithID(id string) (Table, error) {
 	return Table{}, errors.New("table not found")
 }
 
+// findTable returns the table of the current state which is the new table t
+// itself or the one t is renamed from.
+func (s State) findTable(t Table) (Table, error) {
+	if found, err := s.findTableWithID(t.Id); err == nil {
+		return found, nil
+	}
+	for _, v := range s.Tables {
+		if t.isSameAs(v) {
+			return v, nil
+		}
+	}
+	return Table{}, errors.New("table not found")
+}
+
+// findNewTable returns the table of the new state which is the current
+// table t itself or renamed from t.
+func (s State) findNewTable(t Table) (Table, error) {
+	if found, err := s.findTableWithID(t.Id); err == nil {
+		return found, nil
+	}
+	for _, v := range s.Tables {
+		if v.isSameAs(t) {
+			return v, nil
+		}
+	}
+	return Table{}, errors.New("table not found")
+}
+
+// findDroppedTables returns the tables of the current state which are not in
+// the new state.
+func (s State) findDroppedTables(new State) []Table {
+	dropped := []Table{}
+	for _, t := range s.Tables {
+		if _, err := new.findNewTable(t); err != nil {
+			dropped = append(dropped, t)
+		}
+	}
+	return dropped
+}
+
+// clearRenamedFrom drops the renamed_from hints once the renames are applied,
+// so that they do not match the objects which reuse the old Ids later.
+func (s *State) clearRenamedFrom() {
+	for i := range s.Tables {
+		s.Tables[i].RenamedFrom = ""
+		for j := range s.Tables[i].Column {
+			s.Tables[i].Column[j].RenamedFrom = ""
+		}
+	}
+}
+
 func (s State) hasTable(t Table) bool {
-	if _, err := s.findTableWithID(t.Id); err != nil {
+	if _, err := s.findTable(t); err != nil {
 		return false
 	}
 	return true
@@ -276,3 +375,51 @@ func (s State) findTablesIn(target State) ([]Table, error) {
 	}
 	return filterd, nil
 }
+
+func (s State) findViewWithID(id string) (View, error) {
+	for _, v := range s.View {
+		if v.Id == id {
+			return v, nil
+		}
+	}
+	return View{}, errors.New("view not found")
+}
+
+// findChangedTableIDs returns the IDs of tables in s which are dropped or
+// altered to become the target state.
+func (s State) findChangedTableIDs(target State) (map[string]bool, error) {
+	ids := map[string]bool{}
+	for _, t := range s.Tables {
+		newTable, err := target.findNewTable(t)
+		if err != nil {
+			ids[t.Id] = true
+			continue
+		}
+		ops := Operations{}
+		if err := ops.UpdateTable(t, newTable); err != nil {
+			return nil, err
+		}
+		if len(ops.Operation) > 0 {
+			ids[t.Id] = true
+		}
+	}
+	return ids, nil
+}
+
+func (s State) findRoutineWithName(name string) (Routine, error) {
+	for _, r := range s.Routine {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+	return Routine{}, errors.New("routine not found")
+}
+
+func (s State) findEventWithName(name string) (Event, error) {
+	for _, e := range s.Event {
+		if e.Name == name {
+			return e, nil
+		}
+	}
+	return Event{}, errors.New("event not found")
+}
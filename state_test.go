@@ -1,9 +1,12 @@
 package migo_test
 
 import (
+	"io/ioutil"
+	"os"
 	"reflect"
 	"testing"
 
+	"github.com/ghodss/yaml"
 	"github.com/meta-closure/migo"
 )
 
@@ -16,6 +19,112 @@ func TestNewStateFromSchema(t *testing.T) {
 		spec          string
 	}
 
+	// fkState is the state of test/parse_test_fk.yml, and of the same schema split into test/multi.
+	fkState := migo.State{
+		Tables: []migo.Table{
+			{
+				Id:   "#/definitions/source_table",
+				Name: "test2",
+				Column: []migo.Column{
+					{
+						Name:    "source_column",
+						Id:      "source_column",
+						Type:    "source_type",
+						Unique:  true,
+						Default: migo.Default{Type: migo.DefaultString, Value: "default_test"},
+					},
+				},
+			},
+			{
+				Id:   "#/definitions/target_table",
+				Name: "test1",
+				Column: []migo.Column{
+					{
+						Id:      "target_column",
+						Name:    "target_column",
+						Type:    "target_type",
+						Unique:  true,
+						Default: migo.Default{Type: migo.DefaultString, Value: "default_test"},
+					},
+				},
+				PrimaryKey: []migo.Key{
+					{
+						Target: []migo.Column{
+							{
+								Id:      "target_column",
+								Name:    "target_column",
+								Type:    "target_type",
+								Unique:  true,
+								Default: migo.Default{Type: migo.DefaultString, Value: "default_test"},
+							},
+						},
+						Name: "test_pk",
+					},
+				},
+			},
+		},
+		ForeignKey: []migo.ForeignKey{
+			{
+				Name:          "fk_test",
+				DeleteCascade: true,
+				SourceColumn: migo.Column{
+					Name:    "source_column",
+					Id:      "source_column",
+					Type:    "source_type",
+					Unique:  true,
+					Default: migo.Default{Type: migo.DefaultString, Value: "default_test"},
+				},
+				SourceTable: migo.Table{
+					Id:   "#/definitions/source_table",
+					Name: "test2",
+					Column: []migo.Column{
+						{
+							Name:    "source_column",
+							Id:      "source_column",
+							Type:    "source_type",
+							Unique:  true,
+							Default: migo.Default{Type: migo.DefaultString, Value: "default_test"},
+						},
+					},
+				},
+				TargetColumn: migo.Column{
+					Id:      "target_column",
+					Name:    "target_column",
+					Type:    "target_type",
+					Unique:  true,
+					Default: migo.Default{Type: migo.DefaultString, Value: "default_test"},
+				},
+				TargetTable: migo.Table{
+					Id:   "#/definitions/target_table",
+					Name: "test1",
+					Column: []migo.Column{
+						{
+							Id:      "target_column",
+							Name:    "target_column",
+							Type:    "target_type",
+							Unique:  true,
+							Default: migo.Default{Type: migo.DefaultString, Value: "default_test"},
+						},
+					},
+					PrimaryKey: []migo.Key{
+						{
+							Target: []migo.Column{
+								{
+									Id:      "target_column",
+									Name:    "target_column",
+									Type:    "target_type",
+									Unique:  true,
+									Default: migo.Default{Type: migo.DefaultString, Value: "default_test"},
+								},
+							},
+							Name: "test_pk",
+						},
+					},
+				},
+			},
+		},
+	}
+
 	cases := []Case{
 		{
 			input: migo.MigrateOption{
@@ -57,7 +166,7 @@ func TestNewStateFromSchema(t *testing.T) {
 								Name:          "column",
 								Type:          "type",
 								Unique:        true,
-								Default:       "default_test",
+								Default:       migo.Default{Type: migo.DefaultString, Value: "default_test"},
 								AutoIncrement: true,
 								AutoUpdate:    true,
 								NotNull:       true,
@@ -71,116 +180,646 @@ func TestNewStateFromSchema(t *testing.T) {
 		},
 		{
 			input: migo.MigrateOption{
-				SchemaFile: "./test/parse_test_fk.yml",
+				SchemaFile: "./test/parse_test_column_attribute.yml",
 				FormatType: "yaml",
 			},
 			expectedState: migo.State{
 				Tables: []migo.Table{
 					{
-						Id:   "#/definitions/source_table",
-						Name: "test2",
+						Id:   "#/definitions/test",
+						Name: "test",
 						Column: []migo.Column{
 							{
-								Name:    "source_column",
-								Id:      "source_column",
-								Type:    "source_type",
-								Unique:  true,
-								Default: "default_test",
+								Id:      "memo",
+								Name:    "memo",
+								Type:    "text",
+								Comment: "user's memo",
+							},
+							{
+								Id:        "token",
+								Name:      "token",
+								Type:      "varchar(255)",
+								Charset:   "utf8mb4",
+								Collation: "utf8mb4_bin",
+							},
+						},
+					},
+				},
+			},
+			spec:      "correct column attribute",
+			isSuccess: true,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_column_attribute.yml",
+				FormatType: "yaml",
+				Schema: migo.SchemaOption{
+					CommentFromDescription: true,
+				},
+			},
+			expectedState: migo.State{
+				Tables: []migo.Table{
+					{
+						Id:   "#/definitions/test",
+						Name: "test",
+						Column: []migo.Column{
+							{
+								Id:      "memo",
+								Name:    "memo",
+								Type:    "text",
+								Comment: "user's memo",
+							},
+							{
+								Id:        "token",
+								Name:      "token",
+								Type:      "varchar(255)",
+								Charset:   "utf8mb4",
+								Collation: "utf8mb4_bin",
+								Comment:   "access token",
 							},
 						},
 					},
+				},
+			},
+			spec:      "column comment from description",
+			isSuccess: true,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_check.yml",
+				FormatType: "yaml",
+				Schema: migo.SchemaOption{
+					CheckConstraint: true,
+				},
+			},
+			expectedState: migo.State{
+				Tables: []migo.Table{
 					{
-						Id:   "#/definitions/target_table",
-						Name: "test1",
+						Id:   "#/definitions/test",
+						Name: "test",
 						Column: []migo.Column{
 							{
-								Id:      "target_column",
-								Name:    "target_column",
-								Type:    "target_type",
-								Unique:  true,
-								Default: "default_test",
+								Id:   "age",
+								Name: "age",
+								Type: "int",
+							},
+							{
+								Id:   "status",
+								Name: "status",
+								Type: "varchar(16)",
+							},
+							{
+								Id:   "verification_code_of_the_two_factor_authentication_app",
+								Name: "verification_code_of_the_two_factor_authentication_app",
+								Type: "varchar(8)",
+							},
+						},
+						Check: []migo.Check{
+							{
+								Name:       "test_age_range_chk",
+								Expression: "age BETWEEN 0 AND 150",
+							},
+							{
+								Name:       "test_status_enum_chk",
+								Expression: "status IN ('active','banned')",
+							},
+							{
+								Name:       "test_status_length_chk",
+								Expression: "CHAR_LENGTH(status) <= 16",
+							},
+							{
+								Name:       "test_status_pattern_chk",
+								Expression: "REGEXP_LIKE(status, '^[a-z]+$')",
+							},
+							{
+								Name:       "test_verification_code_of_the_two_factor_authentica_474bb3c5_chk",
+								Expression: "CHAR_LENGTH(verification_code_of_the_two_factor_authentication_app) <= 8",
+							},
+						},
+					},
+				},
+			},
+			spec:      "correct check constraint",
+			isSuccess: true,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_json_schema_check.yml",
+				FormatType: "yaml",
+				Schema: migo.SchemaOption{
+					JSONSchemaCheck: true,
+				},
+			},
+			expectedState: migo.State{
+				Tables: []migo.Table{
+					{
+						Id:   "#/definitions/test",
+						Name: "test",
+						Column: []migo.Column{
+							{
+								Id:   "payload",
+								Name: "payload",
+								Type: "json",
+							},
+						},
+						Check: []migo.Check{
+							{
+								Name:       "test_payload_schema_chk",
+								Expression: `JSON_SCHEMA_VALID('{"properties":{"id":{"type":"integer"},"tags":{"items":{"type":"string"},"type":"array"}},"required":["id"],"type":"object"}', payload)`,
+							},
+						},
+					},
+				},
+			},
+			spec:      "correct json schema check constraint",
+			isSuccess: true,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_type_inference.yml",
+				FormatType: "yaml",
+			},
+			expectedState: migo.State{
+				Tables: []migo.Table{
+					{
+						Id:   "#/definitions/test",
+						Name: "test",
+						Column: []migo.Column{
+							{
+								Id:   "code",
+								Name: "code",
+								Type: "varchar(8)",
+							},
+							{
+								Id:       "created_at",
+								Name:     "created_at",
+								Type:     "datetime",
+								Inferred: true,
+							},
+							{
+								Id:       "id",
+								Name:     "id",
+								Type:     "int",
+								NotNull:  true,
+								Inferred: true,
+							},
+							{
+								Id:       "name",
+								Name:     "name",
+								Type:     "varchar(255)",
+								Inferred: true,
+							},
+							{
+								Id:   "note",
+								Name: "note",
+								Type: "varchar(64)",
+							},
+						},
+					},
+				},
+			},
+			spec:      "infer column type from json schema",
+			isSuccess: true,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_type_inference.yml",
+				FormatType: "yaml",
+				Schema: migo.SchemaOption{
+					TypeMappingFile: "./test/type_mapping.yml",
+				},
+			},
+			expectedState: migo.State{
+				Tables: []migo.Table{
+					{
+						Id:   "#/definitions/test",
+						Name: "test",
+						Column: []migo.Column{
+							{
+								Id:   "code",
+								Name: "code",
+								Type: "varchar(8)",
+							},
+							{
+								Id:       "created_at",
+								Name:     "created_at",
+								Type:     "datetime",
+								Inferred: true,
+							},
+							{
+								Id:       "id",
+								Name:     "id",
+								Type:     "bigint",
+								NotNull:  true,
+								Inferred: true,
+							},
+							{
+								Id:       "name",
+								Name:     "name",
+								Type:     "varchar(255)",
+								Inferred: true,
+							},
+							{
+								Id:   "note",
+								Name: "note",
+								Type: "varchar(64)",
+							},
+						},
+					},
+				},
+			},
+			spec:      "infer column type with type mapping file",
+			isSuccess: true,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_default.yml",
+				FormatType: "yaml",
+			},
+			expectedState: migo.State{
+				Tables: []migo.Table{
+					{
+						Id:   "#/definitions/test",
+						Name: "test",
+						Column: []migo.Column{
+							{
+								Id:      "count",
+								Name:    "count",
+								Type:    "int",
+								Default: migo.Default{Type: migo.DefaultNumber, Value: "0"},
+							},
+							{
+								Id:      "id",
+								Name:    "id",
+								Type:    "char(36)",
+								Default: migo.Default{Type: migo.DefaultExpression, Value: "UUID()"},
+							},
+							{
+								Id:      "memo",
+								Name:    "memo",
+								Type:    "varchar(255)",
+								Default: migo.Default{Type: migo.DefaultNull},
+							},
+							{
+								Id:      "status",
+								Name:    "status",
+								Type:    "varchar(16)",
+								Default: migo.Default{Type: migo.DefaultString, Value: "it's active"},
+							},
+						},
+					},
+				},
+			},
+			spec:      "correct default",
+			isSuccess: true,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_generated_column.yml",
+				FormatType: "yaml",
+			},
+			expectedState: migo.State{
+				Tables: []migo.Table{
+					{
+						Id:   "#/definitions/test",
+						Name: "test",
+						Column: []migo.Column{
+							{
+								Id:         "total",
+								Name:       "total",
+								Type:       "int",
+								Expression: "price * quantity",
+								Storage:    "stored",
+							},
+						},
+					},
+				},
+			},
+			spec:      "correct generated column",
+			isSuccess: true,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_view.yml",
+				FormatType: "yaml",
+			},
+			expectedState: migo.State{
+				Tables: []migo.Table{
+					{
+						Id:   "#/definitions/user",
+						Name: "user",
+						Column: []migo.Column{
+							{
+								Id:   "id",
+								Name: "id",
+								Type: "int",
+							},
+						},
+					},
+				},
+				View: []migo.View{
+					{
+						Id:         "#/definitions/active_user",
+						Name:       "active_user",
+						Definition: "SELECT id FROM user",
+						Algorithm:  "merge",
+						Security:   "invoker",
+						DependsOn:  []string{"#/definitions/user"},
+					},
+				},
+			},
+			spec:      "correct view",
+			isSuccess: true,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_trigger.yml",
+				FormatType: "yaml",
+			},
+			expectedState: migo.State{
+				Tables: []migo.Table{
+					{
+						Id:   "#/definitions/test",
+						Name: "test",
+						Column: []migo.Column{
+							{
+								Id:   "updated_by",
+								Name: "updated_by",
+								Type: "varchar(255)",
+							},
+						},
+						Trigger: []migo.Trigger{
+							{
+								Name:   "test_audit",
+								Timing: "before",
+								Event:  "update",
+								Body:   "SET NEW.updated_by = CURRENT_USER()",
+							},
+						},
+					},
+				},
+			},
+			spec:      "correct trigger",
+			isSuccess: true,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_data.yml",
+				FormatType: "yaml",
+			},
+			expectedState: migo.State{
+				Tables: []migo.Table{
+					{
+						Id:   "#/definitions/user",
+						Name: "user",
+						Column: []migo.Column{
+							{
+								Id:   "full_name",
+								Name: "full_name",
+								Type: "varchar(255)",
+							},
+							{
+								Id:   "id",
+								Name: "id",
+								Type: "int",
 							},
 						},
 						PrimaryKey: []migo.Key{
 							{
 								Target: []migo.Column{
 									{
-										Id:      "target_column",
-										Name:    "target_column",
-										Type:    "target_type",
-										Unique:  true,
-										Default: "default_test",
+										Id:   "id",
+										Name: "id",
+										Type: "int",
 									},
 								},
-								Name: "test_pk",
+								Name: "user_pk",
+							},
+						},
+						Data: []migo.DataStep{
+							{
+								Name:      "fill_full_name",
+								Timing:    "after",
+								Column:    "full_name",
+								SQL:       "UPDATE user SET full_name = name WHERE {{chunk}}",
+								Rollback:  "UPDATE user SET full_name = ''",
+								ChunkSize: 1000,
 							},
 						},
 					},
 				},
-				ForeignKey: []migo.ForeignKey{
+			},
+			spec:      "correct data step",
+			isSuccess: true,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_data_fail_by_chunk_key.yml",
+				FormatType: "yaml",
+			},
+			spec:      "chunked data step by non-integer primary key",
+			isSuccess: false,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_partition.yml",
+				FormatType: "yaml",
+			},
+			expectedState: migo.State{
+				Tables: []migo.Table{
 					{
-						Name:          "fk_test",
-						DeleteCascade: true,
-						SourceColumn: migo.Column{
-							Name:    "source_column",
-							Id:      "source_column",
-							Type:    "source_type",
-							Unique:  true,
-							Default: "default_test",
+						Id:   "#/definitions/event",
+						Name: "event",
+						Column: []migo.Column{
+							{
+								Id:   "created_at",
+								Name: "created_at",
+								Type: "date",
+							},
+							{
+								Id:   "id",
+								Name: "id",
+								Type: "int",
+							},
 						},
-						SourceTable: migo.Table{
-							Id:   "#/definitions/source_table",
-							Name: "test2",
-							Column: []migo.Column{
-								{
-									Name:    "source_column",
-									Id:      "source_column",
-									Type:    "source_type",
-									Unique:  true,
-									Default: "default_test",
+						PrimaryKey: []migo.Key{
+							{
+								Target: []migo.Column{
+									{
+										Id:   "created_at",
+										Name: "created_at",
+										Type: "date",
+									},
+									{
+										Id:   "id",
+										Name: "id",
+										Type: "int",
+									},
 								},
+								Name: "event_pk",
 							},
 						},
-						TargetColumn: migo.Column{
-							Id:      "target_column",
-							Name:    "target_column",
-							Type:    "target_type",
-							Unique:  true,
-							Default: "default_test",
+						Partition: migo.Partition{
+							Type:    "range",
+							Columns: []string{"created_at"},
+							Partitions: migo.PartitionDefinitions{
+								{Name: "p202601", Values: "'2026-02-01'"},
+								{Name: "pmax", Values: "MAXVALUE"},
+							},
 						},
-						TargetTable: migo.Table{
-							Id:   "#/definitions/target_table",
-							Name: "test1",
-							Column: []migo.Column{
-								{
-									Id:      "target_column",
-									Name:    "target_column",
-									Type:    "target_type",
-									Unique:  true,
-									Default: "default_test",
-								},
+					},
+				},
+			},
+			spec:      "correct partition",
+			isSuccess: true,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_fail_by_partition.yml",
+				FormatType: "yaml",
+			},
+			spec:      "partition column is not part of primary key",
+			isSuccess: false,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/lint_meta_schema_test.yml",
+				FormatType: "yaml",
+			},
+			spec:      "unknown key in column",
+			isSuccess: false,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile:  "./test/parse_test_overlay.yml",
+				FormatType:  "yaml",
+				Environment: "production",
+			},
+			expectedState: migo.State{
+				Tables: []migo.Table{
+					{
+						Id:   "#/definitions/user",
+						Name: "user",
+						Column: []migo.Column{
+							{
+								Id:   "email",
+								Name: "email",
+								Type: "varchar(255)",
 							},
-							PrimaryKey: []migo.Key{
-								{
-									Target: []migo.Column{
-										{
-											Id:      "target_column",
-											Name:    "target_column",
-											Type:    "target_type",
-											Unique:  true,
-											Default: "default_test",
-										},
+							{
+								Id:   "name",
+								Name: "name",
+								Type: "varchar(255)",
+							},
+						},
+						Index: []migo.Key{
+							{
+								Name: "user_name_email_idx",
+								Target: []migo.Column{
+									{
+										Id:   "email",
+										Name: "email",
+										Type: "varchar(255)",
+									},
+									{
+										Id:   "name",
+										Name: "name",
+										Type: "varchar(255)",
 									},
-									Name: "test_pk",
 								},
 							},
 						},
 					},
 				},
 			},
-			spec:      "correct foreign key setting",
+			spec:      "correct overlay of environment",
+			isSuccess: true,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_routine.yml",
+				FormatType: "yaml",
+			},
+			expectedState: migo.State{
+				Tables: []migo.Table{
+					{
+						Id:   "#/definitions/user",
+						Name: "user",
+						Column: []migo.Column{
+							{
+								Id:   "created_at",
+								Name: "created_at",
+								Type: "datetime",
+							},
+						},
+					},
+				},
+				Routine: []migo.Routine{
+					{
+						Name:            "user_count",
+						Type:            "function",
+						Parameters:      []string{"since DATETIME"},
+						Returns:         "INT",
+						Characteristics: "READS SQL DATA",
+						Body:            "RETURN (SELECT COUNT(*) FROM user WHERE created_at > since)",
+						Checksum:        "5535a98c881d92ca4820679bc12482cc85176b333ab4b78f400cc0172daf8000",
+					},
+				},
+				Event: []migo.Event{
+					{
+						Name:     "purge_session",
+						Schedule: "EVERY 1 DAY",
+						Body:     "DELETE FROM session WHERE expired_at < NOW()",
+						Checksum: "620857ea3deb2a9df32a69f12624a241eec1cb91afa20d0b2eeec3b27995930e",
+					},
+				},
+			},
+			spec:      "correct routine and event",
 			isSuccess: true,
 		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_routine_fail_by_name.yml",
+				FormatType: "yaml",
+			},
+			spec:      "routine with name field",
+			isSuccess: false,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_event_fail_by_checksum.yml",
+				FormatType: "yaml",
+			},
+			spec:      "event with checksum field",
+			isSuccess: false,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/parse_test_fk.yml",
+				FormatType: "yaml",
+			},
+			expectedState: fkState,
+			spec:          "correct foreign key setting",
+			isSuccess:     true,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/multi",
+				FormatType: "yaml",
+			},
+			expectedState: fkState,
+			spec:          "correct foreign key across schema files",
+			isSuccess:     true,
+		},
+		{
+			input: migo.MigrateOption{
+				SchemaFile: "./test/multi/source.yml,./test/multi/target.yml",
+				FormatType: "yaml",
+			},
+			expectedState: fkState,
+			spec:          "correct list of schema files",
+			isSuccess:     true,
+		},
 		{
 			input: migo.MigrateOption{
 				SchemaFile: "./test/parse_test_pk.yml",
@@ -197,7 +836,7 @@ func TestNewStateFromSchema(t *testing.T) {
 								Name:    "test_column",
 								Type:    "test_type",
 								Unique:  true,
-								Default: "default_test",
+								Default: migo.Default{Type: migo.DefaultString, Value: "default_test"},
 							},
 						},
 						PrimaryKey: []migo.Key{
@@ -209,7 +848,7 @@ func TestNewStateFromSchema(t *testing.T) {
 										Name:    "test_column",
 										Type:    "test_type",
 										Unique:  true,
-										Default: "default_test",
+										Default: migo.Default{Type: migo.DefaultString, Value: "default_test"},
 									},
 								},
 							},
@@ -236,7 +875,7 @@ func TestNewStateFromSchema(t *testing.T) {
 								Name:    "test_column",
 								Type:    "test_type",
 								Unique:  true,
-								Default: "default_test",
+								Default: migo.Default{Type: migo.DefaultString, Value: "default_test"},
 							},
 						},
 						Index: []migo.Key{
@@ -248,7 +887,7 @@ func TestNewStateFromSchema(t *testing.T) {
 										Name:    "test_column",
 										Type:    "test_type",
 										Unique:  true,
-										Default: "default_test",
+										Default: migo.Default{Type: migo.DefaultString, Value: "default_test"},
 									},
 								},
 							},
@@ -268,7 +907,7 @@ func TestNewStateFromSchema(t *testing.T) {
 			continue
 		}
 
-		s, err := migo.NewStateFromSchema(h)
+		s, err := migo.NewStateFromSchema(h, c.input.Schema)
 		if !c.isSuccess && err == nil {
 			t.Errorf("in %s, error is expected but null", c.spec)
 			continue
@@ -290,3 +929,80 @@ func TestNewStateFromSchema(t *testing.T) {
 		}
 	}
 }
+
+func TestNewStateFromYAML(t *testing.T) {
+
+	type Case struct {
+		input     migo.Default
+		isSuccess bool
+		spec      string
+	}
+
+	cases := []Case{
+		{
+			input:     migo.Default{},
+			isSuccess: true,
+			spec:      "no default",
+		},
+		{
+			input:     migo.Default{Type: migo.DefaultString, Value: ""},
+			isSuccess: true,
+			spec:      "empty string default",
+		},
+		{
+			input:     migo.Default{Type: migo.DefaultString, Value: "default_test"},
+			isSuccess: true,
+			spec:      "string default",
+		},
+		{
+			input:     migo.Default{Type: migo.DefaultNull},
+			isSuccess: true,
+			spec:      "null default",
+		},
+		{
+			input:     migo.Default{Type: migo.DefaultExpression, Value: "UUID()"},
+			isSuccess: true,
+			spec:      "expression default",
+		},
+	}
+
+	for _, c := range cases {
+		s := migo.NewState()
+		s.Tables = []migo.Table{
+			{
+				Id:     "#/definitions/test",
+				Name:   "test",
+				Column: []migo.Column{{Id: "name", Name: "name", Type: "varchar(8)", Default: c.input}},
+			},
+		}
+
+		y, err := yaml.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := ioutil.TempFile("", "migo-state-*.yml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(f.Name())
+		f.Write(y)
+		f.Close()
+
+		loaded, err := migo.NewStateFromYAML(f.Name())
+		if !c.isSuccess && err == nil {
+			t.Errorf("in %s, error is expected but null", c.spec)
+			continue
+		}
+		if c.isSuccess && err != nil {
+			t.Errorf("in %s, catche the unexpected error %s", c.spec, err)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if !reflect.DeepEqual(s.Tables, loaded.Tables) {
+			t.Errorf("in %s, expected tables are %+v\n, but actual %+v\n", c.spec, s.Tables, loaded.Tables)
+		}
+	}
+}
@@ -9,11 +9,17 @@ import (
 )
 
 type Table struct {
-	Id         string  `json:"id"`
-	Name       string  `json:"name"`
-	PrimaryKey Keys    `json"primary_key"`
-	Index      Keys    `json:"index"`
-	Column     Columns `json:"column"`
+	Id          string    `json:"id"`
+	RenamedFrom string    `json:"renamed_from"`
+	Name        string    `json:"name"`
+	PrimaryKey  Keys      `json"primary_key"`
+	Index       Keys      `json:"index"`
+	Column      Columns   `json:"column"`
+	Check       Checks    `json:"check"`
+	Trigger     Triggers  `json:"trigger"`
+	Data        DataSteps `json:"data"`
+	Partition   Partition `json:"partition"`
+	LintIgnore  []string  `json:"lint_ignore"`
 }
 
 type Tables []Table
@@ -62,6 +68,26 @@ func (t Table) findPrimaryKey(m map[string]interface{}) ([]Key, error) {
 	return t.findKeys(m["primary_key"])
 }
 
+func (t Table) findTriggers(m map[string]interface{}) ([]Trigger, error) {
+	if m["trigger"] == nil {
+		return nil, nil
+	}
+	ts, ok := m["trigger"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("fail to convert type to map[string]interface{}")
+	}
+
+	triggers := []Trigger{}
+	for k, v := range ts {
+		trigger := NewTrigger(k)
+		if err := trigger.read(v); err != nil {
+			return nil, errors.Wrapf(err, "reading trigger %s", k)
+		}
+		triggers = append(triggers, trigger)
+	}
+	return triggers, nil
+}
+
 func (t Table) findIndex(m map[string]interface{}) ([]Key, error) {
 	if m["index"] == nil {
 		return nil, nil
@@ -69,7 +95,7 @@ func (t Table) findIndex(m map[string]interface{}) ([]Key, error) {
 	return t.findKeys(m["index"])
 }
 
-func (t *Table) read(schema *schema.Schema) error {
+func (t *Table) read(schema *schema.Schema, op SchemaOption) error {
 	if hasNotTable(schema) {
 		return nil
 	}
@@ -80,16 +106,39 @@ func (t *Table) read(schema *schema.Schema) error {
 	if err := t.setName(m["name"]); err != nil {
 		return errors.Wrap(err, "setting name to table")
 	}
+	if m["renamed_from"] != nil {
+		s, ok := m["renamed_from"].(string)
+		if !ok {
+			return fmt.Errorf("fail to convert string type from %v", m["renamed_from"])
+		}
+		t.RenamedFrom = s
+	}
 
 	for k, s := range schema.Properties {
 		if hasNotColumn(*s) {
 			continue
 		}
 		c := NewColumn(k)
-		if err := c.read(*s); err != nil {
+		if err := c.read(*s, isRequired(schema, k), op); err != nil {
 			return errors.Wrap(err, "reading columns")
 		}
 		t.Column = append(t.Column, c)
+
+		if op.CheckConstraint {
+			checks, err := findChecks(*t, c, *s)
+			if err != nil {
+				return errors.Wrapf(err, "reading check constraints of column %s", k)
+			}
+			t.Check = append(t.Check, checks...)
+		}
+
+		if op.JSONSchemaCheck {
+			checks, err := findJSONSchemaCheck(*t, c, *s)
+			if err != nil {
+				return errors.Wrapf(err, "reading json schema of column %s", k)
+			}
+			t.Check = append(t.Check, checks...)
+		}
 	}
 
 	var err error
@@ -101,9 +150,34 @@ func (t *Table) read(schema *schema.Schema) error {
 	if err != nil {
 		return errors.Wrap(err, "setting index")
 	}
+	t.Trigger, err = t.findTriggers(m)
+	if err != nil {
+		return errors.Wrap(err, "setting trigger")
+	}
+	t.Data, err = t.findDataSteps(m)
+	if err != nil {
+		return errors.Wrap(err, "setting data step")
+	}
+	t.Partition, err = t.findPartition(m)
+	if err != nil {
+		return errors.Wrap(err, "setting partition")
+	}
+	t.LintIgnore, err = t.findLintIgnore(m)
+	if err != nil {
+		return errors.Wrap(err, "setting lint_ignore")
+	}
 	return nil
 }
 
+func isRequired(schema *schema.Schema, key string) bool {
+	for _, r := range schema.Required {
+		if r == key {
+			return true
+		}
+	}
+	return false
+}
+
 func (t *Table) setName(i interface{}) error {
 	s, ok := i.(string)
 	if !ok {
@@ -154,9 +228,120 @@ func (t Table) findColumnWithID(id string) (Column, error) {
 	return Column{}, errors.New("column not found")
 }
 
+// findColumn returns the column of the current table which is the new
+// column c itself or the one c is renamed from.
+func (t Table) findColumn(c Column) (Column, error) {
+	if found, err := t.findColumnWithID(c.Id); err == nil {
+		return found, nil
+	}
+	for _, v := range t.Column {
+		if c.isSameAs(v) {
+			return v, nil
+		}
+	}
+	return Column{}, errors.New("column not found")
+}
+
+// findNewColumn returns the column of the new table which is the current
+// column c itself or renamed from c.
+func (t Table) findNewColumn(c Column) (Column, error) {
+	if found, err := t.findColumnWithID(c.Id); err == nil {
+		return found, nil
+	}
+	for _, v := range t.Column {
+		if v.isSameAs(c) {
+			return v, nil
+		}
+	}
+	return Column{}, errors.New("column not found")
+}
+
 func (t Table) hasColumn(c Column) bool {
-	if _, err := t.findColumnWithID(c.Id); err != nil {
+	if _, err := t.findColumn(c); err != nil {
 		return false
 	}
 	return true
 }
+
+// isSameAs reports whether the table is the current table itself or renamed
+// from it by a renamed_from hint of the table.
+func (t Table) isSameAs(current Table) bool {
+	if t.Id == current.Id {
+		return true
+	}
+	return t.RenamedFrom != "" && t.RenamedFrom == current.Id
+}
+
+// looksLike reports whether the target seems to be the same table with
+// another Id, which is used to warn about a drop and create without a hint.
+func (t Table) looksLike(target Table) bool {
+	if len(t.Column) == 0 || len(t.Column) != len(target.Column) {
+		return false
+	}
+	for _, c := range t.Column {
+		found := false
+		for _, v := range target.Column {
+			if c.Name == v.Name && c.looksLike(v) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (t Table) findCheckWithName(name string) (Check, error) {
+	for _, c := range t.Check {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return Check{}, errors.New("check not found")
+}
+
+func (t Table) hasCheck(c Check) bool {
+	if _, err := t.findCheckWithName(c.Name); err != nil {
+		return false
+	}
+	return true
+}
+
+func (t Table) findInferredColumns() Columns {
+	cs := Columns{}
+	for _, c := range t.Column {
+		if c.Inferred {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+func (t Table) findTriggerWithName(name string) (Trigger, error) {
+	for _, tr := range t.Trigger {
+		if tr.Name == name {
+			return tr, nil
+		}
+	}
+	return Trigger{}, errors.New("trigger not found")
+}
+
+// hasColumnChangesFrom reports whether any column of the target is dropped
+// or changed, which invalidates the triggers referring to it.
+func (t Table) hasColumnChangesFrom(target Table) (bool, error) {
+	for _, c := range target.Column {
+		new, err := t.findNewColumn(c)
+		if err != nil {
+			return true, nil
+		}
+		isUpdated, err := new.isUpdatedFrom(c)
+		if err != nil {
+			return false, err
+		}
+		if isUpdated {
+			return true, nil
+		}
+	}
+	return false, nil
+}
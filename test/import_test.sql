@@ -0,0 +1,35 @@
+-- dumped schema
+/*!40101 SET NAMES utf8mb4 */;
+DROP TABLE IF EXISTS `user`;
+CREATE TABLE `user` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `email` varchar(255) NOT NULL COMMENT 'login; address',
+  `name` varchar(64) DEFAULT 'anonymous',
+  `score` decimal(10,2) DEFAULT -1.5,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `deleted_at` datetime DEFAULT NULL,
+  `code` varchar(8) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `email` (`email`),
+  UNIQUE KEY `uk_code` (`code`),
+  KEY `idx_name` (`name`),
+  UNIQUE KEY `uk_name_score` (`name`, `score`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE post (
+  id int NOT NULL,
+  user_id int(11) unsigned NOT NULL,
+  editor_id int(11) unsigned,
+  title varchar(255),
+  status enum('draft','published') NOT NULL DEFAULT 'draft',
+  PRIMARY KEY (id),
+  FULLTEXT KEY ft_title (title),
+  CHECK (id > 0)
+) ENGINE=InnoDB
+PARTITION BY HASH (id) PARTITIONS 4;
+
+CREATE INDEX idx_title ON post (title(10));
+ALTER TABLE post ADD CONSTRAINT fk_post_user FOREIGN KEY (user_id) REFERENCES `user` (id) ON DELETE CASCADE;
+ALTER TABLE post ADD CONSTRAINT fk_post_editor FOREIGN KEY (editor_id) REFERENCES `user` (id) ON UPDATE SET NULL ON DELETE CASCADE;
+CREATE VIEW recent AS SELECT * FROM post;
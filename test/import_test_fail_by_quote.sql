@@ -0,0 +1,3 @@
+CREATE TABLE user (
+  name varchar(64) DEFAULT 'anonymous
+);
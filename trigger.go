@@ -0,0 +1,88 @@
+package migo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type Trigger struct {
+	Name   string `json:"name"`
+	Timing string `json:"timing"`
+	Event  string `json:"event"`
+	Body   string `json:"body"`
+}
+
+type Triggers []Trigger
+
+func (t Triggers) Len() int {
+	return len(t)
+}
+
+func (t Triggers) Less(i, j int) bool {
+	return t[i].Name < t[j].Name
+}
+
+func (t Triggers) Swap(i, j int) {
+	t[i], t[j] = t[j], t[i]
+}
+
+func NewTrigger(name string) Trigger {
+	return Trigger{Name: name}
+}
+
+func (t *Trigger) read(i interface{}) error {
+	m, ok := i.(map[string]interface{})
+	if !ok {
+		return errors.New("fail to convert type to map[string]interface{}")
+	}
+
+	for _, f := range []struct {
+		key string
+		dst *string
+	}{
+		{"timing", &t.Timing},
+		{"event", &t.Event},
+		{"body", &t.Body},
+	} {
+		if m[f.key] == nil {
+			return fmt.Errorf("%s is not found", f.key)
+		}
+		s, ok := m[f.key].(string)
+		if !ok {
+			return fmt.Errorf("fail to convert string type from %v", m[f.key])
+		}
+		*f.dst = s
+	}
+
+	switch strings.ToUpper(t.Timing) {
+	case "BEFORE", "AFTER":
+	default:
+		return fmt.Errorf("timing %s is invalid", t.Timing)
+	}
+	switch strings.ToUpper(t.Event) {
+	case "INSERT", "UPDATE", "DELETE":
+	default:
+		return fmt.Errorf("event %s is invalid", t.Event)
+	}
+	return nil
+}
+
+func (t Trigger) isUpdatedFrom(target Trigger) (bool, error) {
+	if t.Name != target.Name {
+		return false, errors.New("the target trigger name is wrong")
+	}
+	return !reflect.DeepEqual(t, target), nil
+}
+
+func (t Trigger) query(table Table) string {
+	return fmt.Sprintf("CREATE TRIGGER %s %s %s ON %s FOR EACH ROW %s",
+		t.Name,
+		strings.ToUpper(t.Timing),
+		strings.ToUpper(t.Event),
+		table.Name,
+		t.Body,
+	)
+}
@@ -0,0 +1,157 @@
+package migo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	schema "github.com/lestrrat/go-jsschema"
+)
+
+const (
+	defaultDialect = "mysql"
+)
+
+// TypeMapping maps JSON Schema types and formats to SQL column types.
+// String is a format string which receives maxLength, and Text is used
+// when maxLength is not given.
+type TypeMapping struct {
+	Integer  string `json:"integer"`
+	Number   string `json:"number"`
+	Boolean  string `json:"boolean"`
+	String   string `json:"string"`
+	Text     string `json:"text"`
+	DateTime string `json:"date-time"`
+	Date     string `json:"date"`
+	Time     string `json:"time"`
+	Object   string `json:"object"`
+	Array    string `json:"array"`
+}
+
+var dialectTypeMappings = map[string]TypeMapping{
+	"mysql": {
+		Integer:  "int",
+		Number:   "double",
+		Boolean:  "tinyint(1)",
+		String:   "varchar(%d)",
+		Text:     "text",
+		DateTime: "datetime",
+		Date:     "date",
+		Time:     "time",
+		Object:   "json",
+		Array:    "json",
+	},
+	"mariadb": {
+		Integer:  "int",
+		Number:   "double",
+		Boolean:  "tinyint(1)",
+		String:   "varchar(%d)",
+		Text:     "text",
+		DateTime: "datetime",
+		Date:     "date",
+		Time:     "time",
+		Object:   "longtext",
+		Array:    "longtext",
+	},
+}
+
+func (m TypeMapping) merge(target TypeMapping) TypeMapping {
+	for _, f := range []struct {
+		dst *string
+		src string
+	}{
+		{&m.Integer, target.Integer},
+		{&m.Number, target.Number},
+		{&m.Boolean, target.Boolean},
+		{&m.String, target.String},
+		{&m.Text, target.Text},
+		{&m.DateTime, target.DateTime},
+		{&m.Date, target.Date},
+		{&m.Time, target.Time},
+		{&m.Object, target.Object},
+		{&m.Array, target.Array},
+	} {
+		if f.src != "" {
+			*f.dst = f.src
+		}
+	}
+	return m
+}
+
+// NewTypeMapping returns the mapping table of the dialect, overridden by
+// the same dialect's entry in filePath when it is given.
+func NewTypeMapping(dialect, filePath string) (TypeMapping, error) {
+	if dialect == "" {
+		dialect = defaultDialect
+	}
+	m, ok := dialectTypeMappings[dialect]
+
+	if filePath != "" {
+		b, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return TypeMapping{}, errors.Wrap(err, "YAML file open error")
+		}
+		y := map[string]interface{}{}
+		if err := yaml.Unmarshal(b, &y); err != nil {
+			return TypeMapping{}, errors.Wrap(err, "YAML file parse error")
+		}
+		if y[dialect] != nil {
+			b, err := json.Marshal(y[dialect])
+			if err != nil {
+				return TypeMapping{}, errors.Wrap(err, "convert to json")
+			}
+			override := TypeMapping{}
+			if err := json.Unmarshal(b, &override); err != nil {
+				return TypeMapping{}, errors.Wrap(err, "convert to type mapping")
+			}
+			m, ok = m.merge(override), true
+		}
+	}
+
+	if !ok {
+		return TypeMapping{}, fmt.Errorf("dialect %s is not supported", dialect)
+	}
+	return m, nil
+}
+
+func primitiveType(s schema.Schema) schema.PrimitiveType {
+	for _, t := range s.Type {
+		if t != schema.NullType {
+			return t
+		}
+	}
+	return schema.UnspecifiedType
+}
+
+func (m TypeMapping) infer(s schema.Schema) (string, error) {
+	switch s.Format {
+	case schema.FormatDateTime:
+		return m.DateTime, nil
+	case "date":
+		return m.Date, nil
+	case "time":
+		return m.Time, nil
+	}
+
+	switch primitiveType(s) {
+	case schema.IntegerType:
+		return m.Integer, nil
+	case schema.NumberType:
+		return m.Number, nil
+	case schema.BooleanType:
+		return m.Boolean, nil
+	case schema.StringType:
+		if s.MaxLength.Initialized {
+			return fmt.Sprintf(m.String, s.MaxLength.Val), nil
+		}
+		return m.Text, nil
+	case schema.ObjectType:
+		return m.Object, nil
+	case schema.ArrayType:
+		return m.Array, nil
+	}
+	return "", errors.New("column type can not be inferred from JSON Schema")
+}
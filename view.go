@@ -0,0 +1,136 @@
+package migo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	schema "github.com/lestrrat/go-jsschema"
+)
+
+type View struct {
+	Id         string   `json:"id"`
+	Name       string   `json:"name"`
+	Definition string   `json:"definition"`
+	Algorithm  string   `json:"algorithm"`
+	Security   string   `json:"security"`
+	DependsOn  []string `json:"depends_on"`
+}
+
+type Views []View
+
+func (v Views) Len() int {
+	return len(v)
+}
+
+func (v Views) Less(i, j int) bool {
+	return v[i].Id < v[j].Id
+}
+
+func (v Views) Swap(i, j int) {
+	v[i], v[j] = v[j], v[i]
+}
+
+func NewView(id string) *View {
+	return &View{Id: id}
+}
+
+func hasNotView(schema *schema.Schema) bool {
+	return schema.Extras["view"] == nil
+}
+
+func (v *View) read(schema *schema.Schema) error {
+	m, ok := schema.Extras["view"].(map[string]interface{})
+	if !ok {
+		return errors.New("convert from interface{} to map[string]interface{}")
+	}
+
+	for _, f := range []struct {
+		key      string
+		dst      *string
+		required bool
+	}{
+		{"name", &v.Name, true},
+		{"definition", &v.Definition, true},
+		{"algorithm", &v.Algorithm, false},
+		{"security", &v.Security, false},
+	} {
+		if m[f.key] == nil {
+			if f.required {
+				return fmt.Errorf("%s is not found", f.key)
+			}
+			continue
+		}
+		s, ok := m[f.key].(string)
+		if !ok {
+			return fmt.Errorf("fail to convert string type from %v", m[f.key])
+		}
+		*f.dst = s
+	}
+
+	switch strings.ToUpper(v.Algorithm) {
+	case "", "UNDEFINED", "MERGE", "TEMPTABLE":
+	default:
+		return fmt.Errorf("algorithm %s is invalid", v.Algorithm)
+	}
+	switch strings.ToUpper(v.Security) {
+	case "", "DEFINER", "INVOKER":
+	default:
+		return fmt.Errorf("security %s is invalid", v.Security)
+	}
+
+	if m["depends_on"] == nil {
+		return nil
+	}
+	l, ok := m["depends_on"].([]interface{})
+	if !ok {
+		return fmt.Errorf("fail to convert []interface{} type from %v", m["depends_on"])
+	}
+	for _, i := range l {
+		s, ok := i.(string)
+		if !ok {
+			return fmt.Errorf("fail to convert string type from %v", i)
+		}
+		v.DependsOn = append(v.DependsOn, s)
+	}
+	return nil
+}
+
+func (v View) resolve(s State) error {
+	for _, id := range v.DependsOn {
+		if _, err := s.findTableWithID(id); err != nil {
+			return fmt.Errorf("JSON Path %s table is not found", id)
+		}
+	}
+	return nil
+}
+
+func (v View) isUpdatedFrom(target View) (bool, error) {
+	if v.Id != target.Id {
+		return false, errors.New("the target view ID is wrong")
+	}
+	return !reflect.DeepEqual(v, target), nil
+}
+
+func (v View) dependsOn(id string) bool {
+	for _, d := range v.DependsOn {
+		if d == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (v View) query() string {
+	s := []string{}
+	if v.Algorithm != "" {
+		s = append(s, fmt.Sprintf("ALGORITHM=%s", strings.ToUpper(v.Algorithm)))
+	}
+	if v.Security != "" {
+		s = append(s, fmt.Sprintf("SQL SECURITY %s", strings.ToUpper(v.Security)))
+	}
+	s = append(s, fmt.Sprintf("VIEW %s AS %s", v.Name, v.Definition))
+	return strings.Join(s, " ")
+}